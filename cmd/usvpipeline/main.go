@@ -9,11 +9,27 @@ import (
 	"syscall"
 	"time"
 
-	"github.com/CAST-Intelligence/elysium-usv/internal/aws"
+	"github.com/CAST-Intelligence/elysium-usv/internal/audit"
 	"github.com/CAST-Intelligence/elysium-usv/internal/azure"
 	"github.com/CAST-Intelligence/elysium-usv/internal/config"
+	"github.com/CAST-Intelligence/elysium-usv/internal/metrics"
 	"github.com/CAST-Intelligence/elysium-usv/internal/server"
+	"github.com/CAST-Intelligence/elysium-usv/internal/storage"
 	"github.com/CAST-Intelligence/elysium-usv/internal/worker"
+
+	// Blank-imported so each driver's init() registers itself with the
+	// storage package; config.Config.Destinations selects among them by
+	// name, so this file never needs to import the drivers by name again.
+	_ "github.com/CAST-Intelligence/elysium-usv/internal/storage/b2"
+	_ "github.com/CAST-Intelligence/elysium-usv/internal/storage/file"
+	_ "github.com/CAST-Intelligence/elysium-usv/internal/storage/gcs"
+	_ "github.com/CAST-Intelligence/elysium-usv/internal/storage/null"
+	_ "github.com/CAST-Intelligence/elysium-usv/internal/storage/s3"
+
+	// Blank-imported so each driver's init() registers itself as a
+	// storage.ObjectStore; cfg.StorageDriver selects among them by name.
+	_ "github.com/CAST-Intelligence/elysium-usv/internal/storage/azureblob"
+	_ "github.com/CAST-Intelligence/elysium-usv/internal/storage/localobjectstore"
 )
 
 func main() {
@@ -36,6 +52,11 @@ func main() {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
+	// Configure the audit log sink and signing key
+	if err := audit.Configure(cfg); err != nil {
+		log.Fatalf("Failed to configure audit log: %v", err)
+	}
+
 	// Initialize clients
 	log.Println("Initializing Azure client...")
 	azureClient, err := azure.NewClient(cfg)
@@ -44,26 +65,46 @@ func main() {
 	}
 	log.Println("Azure client initialized successfully")
 
-	log.Println("Initializing S3 client...")
-	s3Client, err := aws.NewS3Client(cfg)
+	// Registry for every worker and cloud client to instrument itself
+	// against; /metrics exposes it once the HTTP server is up.
+	metricsRegistry := metrics.NewRegistry()
+
+	log.Println("Initializing storage destinations...")
+	volumes, err := storage.NewAll(cfg, metricsRegistry)
+	if err != nil {
+		log.Fatalf("Failed to create storage destinations: %v", err)
+	}
+	log.Printf("%d storage destination(s) initialized successfully", len(volumes))
+
+	log.Printf("Initializing primary object store (driver=%s)...", cfg.StorageDriver)
+	objectStore, err := storage.NewObjectStore(cfg, metricsRegistry)
 	if err != nil {
-		log.Fatalf("Failed to create S3 client: %v", err)
+		log.Fatalf("Failed to create primary object store: %v", err)
 	}
-	log.Println("S3 client initialized successfully")
 
 	// Initialize workers
 	log.Println("Initializing workers...")
-	validationWorker := worker.NewValidationWorker(cfg, azureClient.BlobClient, azureClient.QueueClient)
-	transferWorker := worker.NewTransferWorker(cfg, azureClient.BlobClient, azureClient.QueueClient, s3Client)
-	cleanupWorker := worker.NewCleanupWorker(cfg, azureClient.BlobClient, azureClient.QueueClient)
-	
+	validationWorker := worker.NewValidationWorker(cfg, objectStore, azureClient.QueueClient, metricsRegistry)
+	transferWorker := worker.NewTransferWorker(cfg, azureClient.BlobClient, azureClient.QueueClient, volumes, metricsRegistry)
+	cleanupWorker := worker.NewCleanupWorker(cfg, azureClient.BlobClient, azureClient.QueueClient, metricsRegistry)
+
 	// Initialize FTP worker if enabled
 	var ftpWorker *worker.FTPWorker
 	if cfg.FTPWatchEnabled {
 		log.Printf("FTP watching enabled, directory: %s", cfg.FTPWatchDir)
-		ftpWorker = worker.NewFTPWorker(cfg, azureClient.BlobClient, azureClient.QueueClient)
+		ftpWorker = worker.NewFTPWorker(cfg, azureClient.BlobClient, azureClient.QueueClient, metricsRegistry)
 	}
-	
+
+	// Initialize secure transfer (SFTP/FTPS) worker if enabled
+	var secureTransferWorker *worker.SecureTransferWorker
+	if cfg.SecureTransferEnabled {
+		log.Printf("Secure transfer watching enabled, protocol: %s", cfg.TransferProtocol)
+		secureTransferWorker, err = worker.NewSecureTransferWorker(cfg, azureClient.BlobClient, azureClient.QueueClient, metricsRegistry)
+		if err != nil {
+			log.Fatalf("Failed to create secure transfer worker: %v", err)
+		}
+	}
+
 	log.Println("Workers initialized successfully")
 
 	// Start workers
@@ -79,22 +120,55 @@ func main() {
 		log.Println("Starting FTP worker...")
 		ftpWorker.Start()
 	}
-	
+
+	// Start secure transfer worker if enabled
+	if secureTransferWorker != nil {
+		log.Println("Starting secure transfer worker...")
+		secureTransferWorker.Start()
+	}
+
 	log.Println("All workers started successfully")
 
 	// Create and enhance server with worker status
 	log.Println("Initializing HTTP server...")
 	srv := server.New(cfg)
+	statusWorkers := map[string]server.ManagedWorker{
+		"validation": validationWorker,
+		"transfer":   transferWorker,
+		"cleanup":    cleanupWorker,
+	}
 	if ftpWorker != nil {
-		server.RegisterWorkerStatusEndpoints(srv, validationWorker, transferWorker, cleanupWorker, ftpWorker)
-	} else {
-		server.RegisterWorkerStatusEndpoints(srv, validationWorker, transferWorker, cleanupWorker)
+		statusWorkers["ftp"] = ftpWorker
+	}
+	if secureTransferWorker != nil {
+		statusWorkers["secure_transfer"] = secureTransferWorker
+	}
+	server.RegisterWorkerStatusEndpoints(srv, statusWorkers)
+	server.RegisterMetrics(metricsRegistry)
+	server.RegisterPoisonLister(poisonListerAdapter{validationWorker.DeadLetterSink()})
+	for _, v := range volumes {
+		if authStatus, ok := v.(server.S3AuthStatus); ok {
+			server.RegisterS3Client(authStatus)
+			break
+		}
 	}
 	log.Printf("HTTP server initialized on port %d", cfg.Port)
-	
+
 	// Start the server
 	go startServer(srv, cfg)
 
+	// Start a standalone metrics server on its own port, if configured
+	var metricsSrv *http.Server
+	if cfg.MetricsListenAddr != "" {
+		metricsSrv = server.NewMetricsServer(cfg)
+		log.Printf("Starting standalone metrics server on %s", cfg.MetricsListenAddr)
+		go func() {
+			if err := metricsSrv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				log.Fatalf("Failed to start metrics server: %v", err)
+			}
+		}()
+	}
+
 	// Prepare shutdown tasks
 	shutdownTasks := []shutdownTask{
 		{
@@ -109,8 +183,26 @@ func main() {
 			name: "cleanup worker",
 			stop: cleanupWorker.Stop,
 		},
+		{
+			name: "storage destinations",
+			stop: func() { closeVolumes(volumes) },
+		},
 	}
 	
+	// Add the standalone metrics server to shutdown tasks if it was started
+	if metricsSrv != nil {
+		shutdownTasks = append(shutdownTasks, shutdownTask{
+			name: "metrics server",
+			stop: func() {
+				shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
+				defer cancel()
+				if err := metricsSrv.Shutdown(shutdownCtx); err != nil {
+					log.Printf("Metrics server shutdown error: %v", err)
+				}
+			},
+		})
+	}
+
 	// Add FTP worker to shutdown tasks if enabled
 	if ftpWorker != nil {
 		shutdownTasks = append(shutdownTasks, shutdownTask{
@@ -118,7 +210,15 @@ func main() {
 			stop: ftpWorker.Stop,
 		})
 	}
-	
+
+	// Add secure transfer worker to shutdown tasks if enabled
+	if secureTransferWorker != nil {
+		shutdownTasks = append(shutdownTasks, shutdownTask{
+			name: "secure transfer worker",
+			stop: secureTransferWorker.Stop,
+		})
+	}
+
 	// Handle graceful shutdown
 	handleGracefulShutdown(ctx, srv, cfg, shutdownTasks)
 }
@@ -129,6 +229,36 @@ type shutdownTask struct {
 	stop func()
 }
 
+// poisonListerAdapter adapts a worker.DeadLetterSink to server.PoisonLister
+// by listing with an empty filter (matching every dead-lettered message),
+// so the server package doesn't need to depend on internal/worker's types.
+type poisonListerAdapter struct {
+	sink worker.DeadLetterSink
+}
+
+func (a poisonListerAdapter) ListAll(ctx context.Context) (interface{}, error) {
+	return a.sink.List(ctx, worker.DeadLetterFilter{})
+}
+
+// closeVolumes closes every volume that wants to release resources on
+// shutdown (e.g. the s3 driver's background credential refresher, the gcs
+// driver's client connection). Most storage.Volume implementations don't
+// need this, so both the no-error and error-returning Close signatures are
+// supported via a type switch rather than adding Close to the Volume
+// interface itself.
+func closeVolumes(volumes []storage.Volume) {
+	for _, v := range volumes {
+		switch c := v.(type) {
+		case interface{ Close() }:
+			c.Close()
+		case interface{ Close() error }:
+			if err := c.Close(); err != nil {
+				log.Printf("Failed to close storage destination: %v", err)
+			}
+		}
+	}
+}
+
 // startServer starts the HTTP server
 func startServer(srv *http.Server, cfg *config.Config) {
 	log.Printf("Starting server on port %d", cfg.Port)
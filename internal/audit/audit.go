@@ -2,88 +2,287 @@ package audit
 
 import (
 	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"log"
 	"os"
 	"path/filepath"
+	"sync"
+	"sync/atomic"
 	"time"
+
+	"github.com/CAST-Intelligence/elysium-usv/internal/config"
 )
 
-// AuditRecord represents an audit record
+// AuditRecord represents a tamper-evident audit record. Records form a
+// Merkle-style chain: PrevHash is the SHA-256 hash of the previous record's
+// canonical JSON serialization, and Signature is an Ed25519 signature over
+// the same serialization, so any edit to an earlier record or reordering of
+// the chain is detectable by VerifyAuditChain.
 type AuditRecord struct {
-	ID            string    `json:"id"`
-	BlobName      string    `json:"blobName"`
-	OperationType string    `json:"operationType"`
-	S3Destination string    `json:"s3Destination"`
-	DeletionTime  time.Time `json:"deletionTime"`
-	CertificateID string    `json:"certificateId"`
+	ID               string    `json:"id"`
+	BlobName         string    `json:"blobName"`
+	OperationType    string    `json:"operationType"`
+	S3Destination    string    `json:"s3Destination"`
+	S3ChecksumSHA256 string    `json:"s3ChecksumSha256,omitempty"`
+	DeletionTime     time.Time `json:"deletionTime"`
+	CertificateID    string    `json:"certificateId"`
+	// Sequence is a monotonically increasing counter assigned at generation
+	// time (see nextSequence), used to order records for chain walking and
+	// verification instead of CertificateID, which embeds only a
+	// second-resolution timestamp and can't be trusted to sort correctly
+	// when multiple certificates are generated within the same second.
+	Sequence  int64  `json:"sequence"`
+	PrevHash  string `json:"prevHash"`
+	Signature string `json:"signature,omitempty"`
+}
+
+// canonicalJSON returns the serialization of the record that is hashed and
+// signed. The signature itself is excluded so that it covers everything
+// else in the record.
+func (r AuditRecord) canonicalJSON() ([]byte, error) {
+	unsigned := r
+	unsigned.Signature = ""
+	return json.Marshal(unsigned)
 }
 
-// GenerateAuditCertificate generates an audit certificate for blob deletion
-// Note: For simplicity, we're using a file-based approach instead of Azure Tables
+// Hash returns the SHA-256 hash of the record's canonical JSON serialization.
+func (r AuditRecord) Hash() (string, error) {
+	data, err := r.canonicalJSON()
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+var (
+	mu         sync.Mutex
+	sink       AuditSink
+	signingKey ed25519.PrivateKey
+
+	// chainMu serializes the read-prevHash/build/sign/append sequence in
+	// generateAuditCertificate. CleanupWorker alone runs two goroutines
+	// (processCleanupQueue and processEmptyTrash) that can both reach it
+	// concurrently; without this, two callers could read the same
+	// LastRecord, compute the same PrevHash, and append two records that
+	// both claim to follow it, forking the chain. It's a separate lock from
+	// mu (which only guards the sink/signingKey package vars across
+	// Configure) so it can be held across the whole sequence without
+	// deadlocking against the nested mu.Lock() calls in
+	// defaultSink()/signRecord().
+	chainMu sync.Mutex
+
+	// seqCounter backs nextSequence. It's seeded from the wall clock at
+	// process start (rather than 0) so sequence numbers keep increasing
+	// across a restart as long as the clock does, and incremented
+	// atomically so concurrent GenerateAuditCertificate calls never hand
+	// out the same value.
+	seqCounter = time.Now().UnixNano()
+)
+
+// nextSequence returns the next value in the process-wide monotonic
+// sequence used to order audit records - see AuditRecord.Sequence.
+func nextSequence() int64 {
+	return atomic.AddInt64(&seqCounter, 1)
+}
+
+// Configure wires the package-level audit sink and signing key from
+// application configuration. It should be called once at startup; callers
+// of GenerateAuditCertificate do not need to know which backend or key is
+// in use.
+func Configure(cfg *config.Config) error {
+	mu.Lock()
+	defer mu.Unlock()
+
+	s, err := newSink(cfg)
+	if err != nil {
+		return fmt.Errorf("failed to configure audit sink: %w", err)
+	}
+	sink = s
+
+	key, err := loadSigningKey(cfg)
+	if err != nil {
+		return fmt.Errorf("failed to load audit signing key: %w", err)
+	}
+	signingKey = key
+
+	return nil
+}
+
+// defaultSink lazily falls back to a filesystem sink rooted at the OS temp
+// directory if Configure was never called, preserving the previous
+// zero-configuration behavior.
+func defaultSink() AuditSink {
+	mu.Lock()
+	defer mu.Unlock()
+	if sink == nil {
+		sink = NewFilesystemSink(filepath.Join(os.TempDir(), "usvpipeline", "audit"))
+	}
+	return sink
+}
+
+// GenerateAuditCertificate generates a tamper-evident audit certificate for
+// a blob deletion and appends it to the configured AuditSink.
 func GenerateAuditCertificate(ctx context.Context, blobName, s3Destination string) error {
-	// Create a record with the current time and blob information
+	return generateAuditCertificate(ctx, blobName, s3Destination, "", "deletion")
+}
+
+// GenerateAuditCertificateForOperation is like GenerateAuditCertificate but
+// allows callers to record an operation type other than "deletion".
+func GenerateAuditCertificateForOperation(ctx context.Context, blobName, s3Destination, operationType string) error {
+	return generateAuditCertificate(ctx, blobName, s3Destination, "", operationType)
+}
+
+// GenerateAuditCertificateWithChecksum is like GenerateAuditCertificate but
+// also records the server-verified SHA256 checksum of the transferred
+// object, so the certificate attests to byte-for-byte integrity at the
+// destination, not just that a deletion occurred.
+func GenerateAuditCertificateWithChecksum(ctx context.Context, blobName, s3Destination, s3ChecksumSHA256 string) error {
+	return generateAuditCertificate(ctx, blobName, s3Destination, s3ChecksumSHA256, "deletion")
+}
+
+func generateAuditCertificate(ctx context.Context, blobName, s3Destination, s3ChecksumSHA256, operationType string) error {
+	s := defaultSink()
+
+	// Hold chainMu for the whole read-prevHash/build/sign/append sequence so
+	// two concurrent callers can't fork the chain - see chainMu's doc
+	// comment.
+	chainMu.Lock()
+	defer chainMu.Unlock()
+
 	now := time.Now().UTC()
 	certificateID := generateCertificateID(blobName, now)
 
-	// Create the audit record
-	record := AuditRecord{
-		ID:            fmt.Sprintf("%s_%s", blobName, certificateID),
-		BlobName:      blobName,
-		OperationType: "deletion",
-		S3Destination: s3Destination,
-		DeletionTime:  now,
-		CertificateID: certificateID,
+	prevHash := ""
+	if last, err := s.LastRecord(ctx); err != nil {
+		log.Printf("Failed to read previous audit record, starting new chain: %v", err)
+	} else if last != nil {
+		if h, err := last.Hash(); err == nil {
+			prevHash = h
+		}
 	}
 
-	// In a production system, this would be stored in Azure Tables
-	// For this implementation, we'll log it and store in a local file for demo purposes
-	log.Printf("Audit certificate generated: %s for blob %s with destination %s", 
-		certificateID, blobName, s3Destination)
+	record := AuditRecord{
+		ID:               fmt.Sprintf("%s_%s", blobName, certificateID),
+		BlobName:         blobName,
+		OperationType:    operationType,
+		S3Destination:    s3Destination,
+		S3ChecksumSHA256: s3ChecksumSHA256,
+		DeletionTime:     now,
+		CertificateID:    certificateID,
+		Sequence:         nextSequence(),
+		PrevHash:         prevHash,
+	}
 
-	// Ensure audit directory exists
-	auditDir := filepath.Join(os.TempDir(), "usvpipeline", "audit")
-	if err := os.MkdirAll(auditDir, 0755); err != nil {
-		return fmt.Errorf("failed to create audit directory: %w", err)
+	if err := signRecord(&record); err != nil {
+		return fmt.Errorf("failed to sign audit record: %w", err)
 	}
 
-	// Write the audit record to a JSON file
-	recordBytes, err := json.MarshalIndent(record, "", "  ")
-	if err != nil {
-		return fmt.Errorf("failed to marshal audit record: %w", err)
+	if err := s.Append(ctx, record); err != nil {
+		return fmt.Errorf("failed to append audit record: %w", err)
 	}
 
-	filename := filepath.Join(auditDir, fmt.Sprintf("audit_%s.json", certificateID))
-	if err := os.WriteFile(filename, recordBytes, 0644); err != nil {
-		return fmt.Errorf("failed to write audit record: %w", err)
+	log.Printf("Audit certificate generated: %s for blob %s with destination %s",
+		certificateID, blobName, s3Destination)
+
+	return nil
+}
+
+func signRecord(record *AuditRecord) error {
+	mu.Lock()
+	key := signingKey
+	mu.Unlock()
+
+	if key == nil {
+		// No signing key configured; leave the record unsigned rather than
+		// failing the whole operation (e.g. local dev without a key).
+		return nil
 	}
 
+	data, err := record.canonicalJSON()
+	if err != nil {
+		return err
+	}
+	record.Signature = hex.EncodeToString(ed25519.Sign(key, data))
 	return nil
 }
 
-// generateCertificateID generates a unique ID for the certificate
+// generateCertificateID generates a unique ID for the certificate.
 func generateCertificateID(blobName string, timestamp time.Time) string {
-	// Format: deletion-{timestamp}-{blobNameHash}
-	return fmt.Sprintf("deletion-%s-%s", timestamp.Format("20060102-150405"), hashString(blobName))
+	sum := sha256.Sum256([]byte(blobName))
+	return fmt.Sprintf("deletion-%s-%s", timestamp.Format("20060102-150405"), hex.EncodeToString(sum[:])[:8])
 }
 
-// hashString creates a simple hash of a string
-func hashString(s string) string {
-	// This is a simple hash for illustration purposes
-	// In a real implementation, we would use a proper hashing algorithm
-	hash := 0
-	for i := 0; i < len(s); i++ {
-		hash = 31*hash + int(s[i])
-	}
-	return fmt.Sprintf("%08x", hash&0xFFFFFFFF)
+// GetAuditRecords retrieves audit records for a given blob from the
+// configured sink.
+func GetAuditRecords(ctx context.Context, blobName string) ([]AuditRecord, error) {
+	return defaultSink().Records(ctx, blobName)
 }
 
-// GetAuditRecords retrieves audit records for a given blob
-// Note: This is a placeholder implementation that doesn't actually query a data store
-func GetAuditRecords(ctx context.Context, blobName string) ([]AuditRecord, error) {
-	// In a production system, this would query Azure Tables
-	// For this implementation, we'll just return an empty slice
-	log.Printf("GetAuditRecords called for blob %s", blobName)
-	return []AuditRecord{}, nil
-}
\ No newline at end of file
+// ChainVerification summarizes the result of VerifyAuditChain.
+type ChainVerification struct {
+	RecordsChecked  int
+	Gaps            []string
+	TamperedRecords []string
+}
+
+// Valid reports whether the chain is intact and every signature verified.
+func (v *ChainVerification) Valid() bool {
+	return len(v.Gaps) == 0 && len(v.TamperedRecords) == 0
+}
+
+// VerifyAuditChain walks the JSON audit files in dir in sequence order,
+// recomputing hashes and validating signatures against the package's
+// configured signing key, and reports any gaps or tampering.
+func VerifyAuditChain(ctx context.Context, dir string) (*ChainVerification, error) {
+	s := NewFilesystemSink(dir)
+	result := &ChainVerification{}
+
+	mu.Lock()
+	var pubKey ed25519.PublicKey
+	if signingKey != nil {
+		pubKey = signingKey.Public().(ed25519.PublicKey)
+	}
+	mu.Unlock()
+
+	prevHash := ""
+	err := s.Walk(ctx, func(record AuditRecord) error {
+		result.RecordsChecked++
+
+		if record.PrevHash != prevHash {
+			result.Gaps = append(result.Gaps, fmt.Sprintf(
+				"record %s: expected prevHash %q, found %q", record.CertificateID, prevHash, record.PrevHash))
+		}
+
+		if pubKey != nil {
+			unsigned := record
+			unsigned.Signature = ""
+			canonical, err := unsigned.canonicalJSON()
+			if err != nil {
+				return err
+			}
+
+			sigBytes, err := hex.DecodeString(record.Signature)
+			if err != nil || !ed25519.Verify(pubKey, canonical, sigBytes) {
+				result.TamperedRecords = append(result.TamperedRecords, record.CertificateID)
+			}
+		}
+
+		hash, err := record.Hash()
+		if err != nil {
+			return err
+		}
+		prevHash = hash
+
+		return nil
+	})
+	if err != nil {
+		return result, fmt.Errorf("failed to walk audit chain: %w", err)
+	}
+
+	return result, nil
+}
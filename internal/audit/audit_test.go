@@ -0,0 +1,116 @@
+package audit
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/CAST-Intelligence/elysium-usv/internal/config"
+)
+
+// TestFilesystemSinkOrdersBySequenceNotCertificateID guards against
+// regressing to CertificateID-string ordering: several records generated
+// within the same wall-clock second (as CleanupWorker's batches routinely
+// do) must still come back from LastRecord/Walk in the order they were
+// appended.
+func TestFilesystemSinkOrdersBySequenceNotCertificateID(t *testing.T) {
+	sink := NewFilesystemSink(t.TempDir())
+	ctx := context.Background()
+
+	now := time.Now().UTC()
+	const n = 5
+	var records []AuditRecord
+	for i := 0; i < n; i++ {
+		seq := nextSequence()
+		record := AuditRecord{
+			ID:            "blob_cert",
+			BlobName:      "blob",
+			OperationType: "deletion",
+			DeletionTime:  now,
+			// Identical CertificateID for every record simulates the
+			// same-second collision CleanupWorker's batches can produce;
+			// only Sequence should determine ordering.
+			CertificateID: "deletion-same-second-id",
+			Sequence:      seq,
+		}
+		if err := sink.Append(ctx, record); err != nil {
+			t.Fatalf("Append() error = %v", err)
+		}
+		records = append(records, record)
+	}
+
+	last, err := sink.LastRecord(ctx)
+	if err != nil {
+		t.Fatalf("LastRecord() error = %v", err)
+	}
+	if last == nil || last.Sequence != records[n-1].Sequence {
+		t.Fatalf("LastRecord() = %+v, want the last-appended record (sequence %d)", last, records[n-1].Sequence)
+	}
+
+	var walked []AuditRecord
+	if err := sink.Walk(ctx, func(r AuditRecord) error {
+		walked = append(walked, r)
+		return nil
+	}); err != nil {
+		t.Fatalf("Walk() error = %v", err)
+	}
+	if len(walked) != n {
+		t.Fatalf("Walk() visited %d records, want %d", len(walked), n)
+	}
+	for i, r := range walked {
+		if r.Sequence != records[i].Sequence {
+			t.Fatalf("Walk() record %d has sequence %d, want %d (out of append order)", i, r.Sequence, records[i].Sequence)
+		}
+	}
+}
+
+// TestGenerateAuditCertificateConcurrentCallsDoNotForkChain guards against
+// regressing the race CleanupWorker can hit in production: its
+// processCleanupQueue and processEmptyTrash goroutines both call into
+// generateAuditCertificate, and without serializing the
+// read-prevHash/build/append sequence, two concurrent calls could both read
+// the same LastRecord and append two records claiming the same PrevHash.
+func TestGenerateAuditCertificateConcurrentCallsDoNotForkChain(t *testing.T) {
+	if err := Configure(&config.Config{AuditDir: t.TempDir()}); err != nil {
+		t.Fatalf("Configure() error = %v", err)
+	}
+
+	const n = 20
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if err := GenerateAuditCertificateForOperation(context.Background(), "blob", "s3://dest", "op"); err != nil {
+				t.Errorf("GenerateAuditCertificateForOperation() error = %v", err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	var records []AuditRecord
+	if err := defaultSink().Walk(context.Background(), func(r AuditRecord) error {
+		records = append(records, r)
+		return nil
+	}); err != nil {
+		t.Fatalf("Walk() error = %v", err)
+	}
+	if len(records) != n {
+		t.Fatalf("Walk() visited %d records, want %d", len(records), n)
+	}
+
+	// A forked chain shows up as more than one record with the same
+	// PrevHash (two records both claiming to follow the same ancestor) or
+	// more than one record with an empty PrevHash (two records both
+	// claiming to start the chain).
+	seenPrevHash := make(map[string]int)
+	for _, r := range records {
+		seenPrevHash[r.PrevHash]++
+	}
+	for prevHash, count := range seenPrevHash {
+		if count > 1 {
+			t.Fatalf("chain forked: %d records share PrevHash %q, want at most 1", count, prevHash)
+		}
+	}
+}
@@ -0,0 +1,198 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/data/aztables"
+	"github.com/CAST-Intelligence/elysium-usv/internal/config"
+)
+
+const (
+	defaultAuditTableName = "auditrecords"
+	auditTimeFormat       = time.RFC3339Nano
+)
+
+// AzureTableSink stores audit records in an Azure Table Storage table. This
+// replaces the filesystem-only stand-in the pipeline previously used for
+// what was a TODO ("In a production system, this would be stored in Azure
+// Tables").
+type AzureTableSink struct {
+	client    *aztables.Client
+	tableName string
+}
+
+// NewAzureTableSink creates an AzureTableSink backed by cfg.AuditTableName,
+// creating the table if it does not already exist.
+func NewAzureTableSink(cfg *config.Config) (*AzureTableSink, error) {
+	tableName := cfg.AuditTableName
+	if tableName == "" {
+		tableName = defaultAuditTableName
+	}
+
+	serviceClient, err := aztables.NewServiceClientFromConnectionString(cfg.AzureStorageConnectionString, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create table service client: %w", err)
+	}
+
+	client := serviceClient.NewClient(tableName)
+	if _, err := client.CreateTable(context.Background(), nil); err != nil && !isTableExistsError(err) {
+		return nil, fmt.Errorf("failed to create audit table: %w", err)
+	}
+
+	return &AzureTableSink{client: client, tableName: tableName}, nil
+}
+
+// auditEntity is the Azure Table Storage row representation of an
+// AuditRecord. PartitionKey groups records by blob so Records() can query a
+// single blob efficiently; RowKey is the zero-padded sequence number so
+// records sort in creation order within a partition - the certificate ID
+// alone can't be trusted for that, since its timestamp component only has
+// second resolution.
+type auditEntity struct {
+	aztables.Entity
+	BlobName         string
+	OperationType    string
+	S3Destination    string
+	S3ChecksumSHA256 string
+	DeletionTime     string
+	CertificateID    string
+	Sequence         int64
+	PrevHash         string
+	Signature        string
+}
+
+func (s *AzureTableSink) Append(ctx context.Context, record AuditRecord) error {
+	entity := toEntity(record)
+	marshalled, err := json.Marshal(entity)
+	if err != nil {
+		return fmt.Errorf("failed to marshal audit entity: %w", err)
+	}
+
+	if _, err := s.client.AddEntity(ctx, marshalled, nil); err != nil {
+		return fmt.Errorf("failed to add audit entity: %w", err)
+	}
+	return nil
+}
+
+func (s *AzureTableSink) LastRecord(ctx context.Context) (*AuditRecord, error) {
+	records, err := s.all(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, nil
+	}
+	return &records[len(records)-1], nil
+}
+
+func (s *AzureTableSink) Records(ctx context.Context, blobName string) ([]AuditRecord, error) {
+	filter := fmt.Sprintf("PartitionKey eq '%s'", blobName)
+	pager := s.client.NewListEntitiesPager(&aztables.ListEntitiesOptions{Filter: &filter})
+
+	var records []AuditRecord
+	for pager.More() {
+		resp, err := pager.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list audit entities: %w", err)
+		}
+		for _, raw := range resp.Entities {
+			record, err := fromEntityBytes(raw)
+			if err != nil {
+				return nil, err
+			}
+			records = append(records, *record)
+		}
+	}
+
+	return records, nil
+}
+
+func (s *AzureTableSink) Walk(ctx context.Context, fn func(AuditRecord) error) error {
+	records, err := s.all(ctx)
+	if err != nil {
+		return err
+	}
+	for _, record := range records {
+		if err := fn(record); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *AzureTableSink) all(ctx context.Context) ([]AuditRecord, error) {
+	pager := s.client.NewListEntitiesPager(nil)
+
+	var records []AuditRecord
+	for pager.More() {
+		resp, err := pager.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list audit entities: %w", err)
+		}
+		for _, raw := range resp.Entities {
+			record, err := fromEntityBytes(raw)
+			if err != nil {
+				return nil, err
+			}
+			records = append(records, *record)
+		}
+	}
+
+	sort.Slice(records, func(i, j int) bool {
+		return records[i].Sequence < records[j].Sequence
+	})
+
+	return records, nil
+}
+
+func toEntity(record AuditRecord) auditEntity {
+	return auditEntity{
+		Entity: aztables.Entity{
+			PartitionKey: record.BlobName,
+			RowKey:       fmt.Sprintf("%020d", record.Sequence),
+		},
+		BlobName:         record.BlobName,
+		OperationType:    record.OperationType,
+		S3Destination:    record.S3Destination,
+		S3ChecksumSHA256: record.S3ChecksumSHA256,
+		DeletionTime:     record.DeletionTime.Format(auditTimeFormat),
+		CertificateID:    record.CertificateID,
+		Sequence:         record.Sequence,
+		PrevHash:         record.PrevHash,
+		Signature:        record.Signature,
+	}
+}
+
+func fromEntityBytes(raw []byte) (*AuditRecord, error) {
+	var entity auditEntity
+	if err := json.Unmarshal(raw, &entity); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal audit entity: %w", err)
+	}
+
+	deletionTime, err := time.Parse(auditTimeFormat, entity.DeletionTime)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse audit entity deletion time: %w", err)
+	}
+
+	return &AuditRecord{
+		ID:               fmt.Sprintf("%s_%s", entity.BlobName, entity.CertificateID),
+		BlobName:         entity.BlobName,
+		OperationType:    entity.OperationType,
+		S3Destination:    entity.S3Destination,
+		S3ChecksumSHA256: entity.S3ChecksumSHA256,
+		DeletionTime:     deletionTime,
+		CertificateID:    entity.CertificateID,
+		Sequence:         entity.Sequence,
+		PrevHash:         entity.PrevHash,
+		Signature:        entity.Signature,
+	}, nil
+}
+
+func isTableExistsError(err error) bool {
+	return err != nil && strings.Contains(err.Error(), "TableAlreadyExists")
+}
@@ -0,0 +1,79 @@
+package audit
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/pem"
+	"fmt"
+	"os"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/Azure/azure-sdk-for-go/sdk/security/keyvault/azsecrets"
+	"github.com/CAST-Intelligence/elysium-usv/internal/config"
+)
+
+// loadSigningKey resolves the Ed25519 private key used to sign audit
+// records. cfg.AuditSigningKeyPath takes precedence; if unset and
+// cfg.AuditSigningKeyVaultSecret is set, the key is fetched from the Azure
+// Key Vault identified by cfg.AzureKeyVaultEndpoint. If neither is
+// configured, GenerateAuditCertificate leaves records unsigned.
+func loadSigningKey(cfg *config.Config) (ed25519.PrivateKey, error) {
+	switch {
+	case cfg.AuditSigningKeyPath != "":
+		return loadSigningKeyFromFile(cfg.AuditSigningKeyPath)
+	case cfg.AuditSigningKeyVaultSecret != "":
+		return loadSigningKeyFromKeyVault(cfg)
+	default:
+		return nil, nil
+	}
+}
+
+func loadSigningKeyFromFile(path string) (ed25519.PrivateKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read signing key file: %w", err)
+	}
+	return decodeSigningKey(data)
+}
+
+func loadSigningKeyFromKeyVault(cfg *config.Config) (ed25519.PrivateKey, error) {
+	if cfg.AzureKeyVaultEndpoint == "" {
+		return nil, fmt.Errorf("AZURE_KEY_VAULT_ENDPOINT is required to load the audit signing key from Key Vault")
+	}
+
+	cred, err := azidentity.NewDefaultAzureCredential(nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create credential for Key Vault: %w", err)
+	}
+
+	client, err := azsecrets.NewClient(cfg.AzureKeyVaultEndpoint, cred, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Key Vault client: %w", err)
+	}
+
+	resp, err := client.GetSecret(context.Background(), cfg.AuditSigningKeyVaultSecret, "", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch signing key secret: %w", err)
+	}
+	if resp.Value == nil {
+		return nil, fmt.Errorf("signing key secret %q has no value", cfg.AuditSigningKeyVaultSecret)
+	}
+
+	return decodeSigningKey([]byte(*resp.Value))
+}
+
+// decodeSigningKey expects a raw 64-byte Ed25519 private key encoded as a
+// PEM block, so the key material has an unambiguous, tool-friendly format
+// on disk and in Key Vault.
+func decodeSigningKey(data []byte) (ed25519.PrivateKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("signing key is not valid PEM")
+	}
+
+	if len(block.Bytes) != ed25519.PrivateKeySize {
+		return nil, fmt.Errorf("signing key has unexpected length %d, want %d", len(block.Bytes), ed25519.PrivateKeySize)
+	}
+
+	return ed25519.PrivateKey(block.Bytes), nil
+}
@@ -0,0 +1,159 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/CAST-Intelligence/elysium-usv/internal/config"
+)
+
+// AuditSink persists audit records and allows the chain to be replayed and
+// verified. Implementations must preserve insertion order so PrevHash
+// chains can be walked deterministically.
+type AuditSink interface {
+	// Append writes a new audit record to the sink.
+	Append(ctx context.Context, record AuditRecord) error
+	// LastRecord returns the most recently appended record, or nil if the
+	// sink is empty.
+	LastRecord(ctx context.Context) (*AuditRecord, error)
+	// Records returns all audit records recorded for a given blob.
+	Records(ctx context.Context, blobName string) ([]AuditRecord, error)
+	// Walk visits every record in the chain in sequence order.
+	Walk(ctx context.Context, fn func(AuditRecord) error) error
+}
+
+// newSink constructs the AuditSink selected by cfg.AuditSinkType.
+func newSink(cfg *config.Config) (AuditSink, error) {
+	switch strings.ToLower(cfg.AuditSinkType) {
+	case "", "filesystem":
+		dir := cfg.AuditDir
+		if dir == "" {
+			dir = filepath.Join(os.TempDir(), "usvpipeline", "audit")
+		}
+		return NewFilesystemSink(dir), nil
+	case "azuretable":
+		return NewAzureTableSink(cfg)
+	default:
+		return nil, fmt.Errorf("unknown audit sink type: %q", cfg.AuditSinkType)
+	}
+}
+
+// FilesystemSink is the original file-based AuditSink: each record is
+// written as its own JSON file, named so that lexicographic order matches
+// AuditRecord.Sequence order - the CertificateID alone isn't enough, since
+// its timestamp component only has second resolution and multiple
+// certificates routinely generate within the same second.
+type FilesystemSink struct {
+	dir string
+}
+
+// NewFilesystemSink creates a FilesystemSink rooted at dir.
+func NewFilesystemSink(dir string) *FilesystemSink {
+	return &FilesystemSink{dir: dir}
+}
+
+func (s *FilesystemSink) Append(ctx context.Context, record AuditRecord) error {
+	if err := os.MkdirAll(s.dir, 0755); err != nil {
+		return fmt.Errorf("failed to create audit directory: %w", err)
+	}
+
+	recordBytes, err := json.MarshalIndent(record, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal audit record: %w", err)
+	}
+
+	filename := filepath.Join(s.dir, fmt.Sprintf("audit_%020d_%s.json", record.Sequence, record.CertificateID))
+	if err := os.WriteFile(filename, recordBytes, 0644); err != nil {
+		return fmt.Errorf("failed to write audit record: %w", err)
+	}
+
+	return nil
+}
+
+func (s *FilesystemSink) LastRecord(ctx context.Context) (*AuditRecord, error) {
+	files, err := s.sortedFiles()
+	if err != nil {
+		return nil, err
+	}
+	if len(files) == 0 {
+		return nil, nil
+	}
+
+	return readAuditFile(files[len(files)-1])
+}
+
+func (s *FilesystemSink) Records(ctx context.Context, blobName string) ([]AuditRecord, error) {
+	var records []AuditRecord
+	err := s.Walk(ctx, func(record AuditRecord) error {
+		if record.BlobName == blobName {
+			records = append(records, record)
+		}
+		return nil
+	})
+	return records, err
+}
+
+func (s *FilesystemSink) Walk(ctx context.Context, fn func(AuditRecord) error) error {
+	files, err := s.sortedFiles()
+	if err != nil {
+		return err
+	}
+
+	for _, f := range files {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		record, err := readAuditFile(f)
+		if err != nil {
+			return fmt.Errorf("failed to read audit record %s: %w", f, err)
+		}
+		if err := fn(*record); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// sortedFiles lists every audit file in s.dir in AuditRecord.Sequence order.
+// This relies on Append naming each file with its record's sequence number
+// zero-padded to a fixed width, so a plain lexicographic sort of filenames
+// is already a sequence sort.
+func (s *FilesystemSink) sortedFiles() ([]string, error) {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read audit directory: %w", err)
+	}
+
+	var files []string
+	for _, e := range entries {
+		if !e.IsDir() && strings.HasSuffix(e.Name(), ".json") {
+			files = append(files, filepath.Join(s.dir, e.Name()))
+		}
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+func readAuditFile(path string) (*AuditRecord, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var record AuditRecord
+	if err := json.Unmarshal(data, &record); err != nil {
+		return nil, err
+	}
+
+	return &record, nil
+}
@@ -0,0 +1,62 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/CAST-Intelligence/elysium-usv/internal/config"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/credentials/ec2rolecreds"
+	"github.com/aws/aws-sdk-go-v2/credentials/stscreds"
+	"github.com/aws/aws-sdk-go-v2/feature/ec2/imds"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+)
+
+// buildCredentialsProvider returns the aws.CredentialsProvider to use for
+// cfg.AWSAuthMode:
+//   - "static" (or empty): the long-lived AWSAccessKey/AWSSecretKey pair
+//   - "iam": EC2/ECS instance-profile credentials fetched over IMDS, for
+//     USV shore-side deployments running on EC2/EKS
+//   - "web-identity": IRSA-style OIDC federation on EKS, exchanging a
+//     projected service account token for role credentials via STS
+//
+// The returned provider is retrieved once by the caller to get a snapshot
+// with its expiration; NewS3Client and the refresh loop both go through
+// this same path so "iam" and "web-identity" are refreshed identically.
+func buildCredentialsProvider(ctx context.Context, cfg *config.Config) (aws.CredentialsProvider, error) {
+	switch strings.ToLower(cfg.AWSAuthMode) {
+	case "", "static":
+		return credentials.NewStaticCredentialsProvider(cfg.AWSAccessKey, cfg.AWSSecretKey, ""), nil
+	case "iam":
+		imdsClient := imds.New(imds.Options{})
+		return ec2rolecreds.New(func(o *ec2rolecreds.Options) {
+			o.Client = imdsClient
+		}), nil
+	case "web-identity":
+		if cfg.AWSRoleARN == "" || cfg.AWSWebIdentityTokenFile == "" {
+			return nil, fmt.Errorf("AWS_ROLE_ARN and AWS_WEB_IDENTITY_TOKEN_FILE are required for web-identity auth")
+		}
+
+		// AssumeRoleWithWebIdentity is an unsigned STS call, so the base
+		// config here needs nothing but a region.
+		stsConfig, err := awsconfig.LoadDefaultConfig(ctx, awsconfig.WithRegion(cfg.AWSRegion))
+		if err != nil {
+			return nil, fmt.Errorf("failed to load base config for STS client: %w", err)
+		}
+		stsClient := sts.NewFromConfig(stsConfig)
+
+		return stscreds.NewWebIdentityRoleProvider(
+			stsClient,
+			cfg.AWSRoleARN,
+			stscreds.IdentityTokenFile(cfg.AWSWebIdentityTokenFile),
+			func(o *stscreds.WebIdentityRoleOptions) {
+				o.RoleSessionName = cfg.AWSRoleSessionName
+			},
+		), nil
+	default:
+		return nil, fmt.Errorf("unsupported AWSAuthMode: %q", cfg.AWSAuthMode)
+	}
+}
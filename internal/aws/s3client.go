@@ -4,34 +4,139 @@ import (
 	"bytes"
 	"context"
 	"crypto/md5"
+	"crypto/sha256"
+	"encoding/base64"
 	"encoding/hex"
+	"errors"
 	"fmt"
+	"hash"
 	"io"
 	"log"
+	"net/http"
+	"sort"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/CAST-Intelligence/elysium-usv/internal/config"
+	"github.com/CAST-Intelligence/elysium-usv/internal/metrics"
 	"github.com/aws/aws-sdk-go-v2/aws"
 	awsconfig "github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/credentials"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	smithymiddleware "github.com/aws/smithy-go/middleware"
+	smithyhttp "github.com/aws/smithy-go/transport/http"
 )
 
-// S3Client represents an AWS S3 client
+// ErrChecksumMismatch is returned by VerifyObject when a freshly-requested
+// HeadObject's server-side checksum doesn't match what was computed locally
+// before upload, so callers (e.g. transfer.transferToVolume) can record a
+// distinct verification-failure status instead of treating it like any
+// other upload error.
+var ErrChecksumMismatch = errors.New("aws: server-side checksum does not match locally computed checksum")
+
+const (
+	defaultPartSize          = 5 * 1024 * 1024 // 5 MiB, S3's minimum multipart part size
+	defaultUploadConcurrency = 5
+
+	// credentialRefreshBuffer is how long before a set of temporary
+	// credentials expire that the background refresher swaps in a freshly
+	// signed client, so an in-flight upload never hits S3 with credentials
+	// that expired mid-request.
+	credentialRefreshBuffer = 5 * time.Minute
+
+	// credentialRefreshRetryInterval is how long the refresher waits before
+	// retrying after a failed refresh attempt (e.g. IMDS or STS unreachable).
+	credentialRefreshRetryInterval = 30 * time.Second
+)
+
+// clockSkewTracker records the delta between this machine's clock and the
+// S3 service's clock (serverTime - localTime), derived from the Date
+// response header seen on every S3 request. Ships often run with a
+// drifting local clock with no reliable NTP source, and SigV4 requests
+// signed too far off the service's clock fail opaquely, so NewS3Client
+// measures skew up front and the observeServerDate middleware keeps
+// re-measuring it from ordinary traffic afterwards.
+type clockSkewTracker struct {
+	mu   sync.RWMutex
+	skew time.Duration
+}
+
+func (t *clockSkewTracker) observe(serverTime time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.skew = serverTime.Sub(time.Now().UTC())
+}
+
+func (t *clockSkewTracker) current() time.Duration {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.skew
+}
+
+// observeServerDateMiddleware records the skew between serverTime (parsed
+// from each response's Date header) and the local clock, so ClockSkew
+// reflects drift measured from ordinary traffic, not just the startup check
+// in NewS3Client.
+func observeServerDateMiddleware(tracker *clockSkewTracker) smithymiddleware.DeserializeMiddleware {
+	return smithymiddleware.DeserializeMiddlewareFunc("ObserveServerDate", func(
+		ctx context.Context, in smithymiddleware.DeserializeInput, next smithymiddleware.DeserializeHandler,
+	) (smithymiddleware.DeserializeOutput, smithymiddleware.Metadata, error) {
+		out, metadata, err := next.HandleDeserialize(ctx, in)
+		if resp, ok := out.RawResponse.(*smithyhttp.Response); ok && resp != nil {
+			if dateHeader := resp.Header.Get("Date"); dateHeader != "" {
+				if serverTime, parseErr := http.ParseTime(dateHeader); parseErr == nil {
+					tracker.observe(serverTime)
+				}
+			}
+		}
+		return out, metadata, err
+	})
+}
+
+// S3Client represents an AWS S3 client. For non-static AWSAuthMode values
+// (EC2/ECS instance-profile or IRSA web-identity credentials), svc is
+// rebuilt and swapped in by a background refresher goroutine before the
+// active credentials expire, following the same pattern Arvados'
+// S3AWSVolume uses to keep long-lived clients authenticated without
+// restarting the process. mu guards svc and the auth fields below so an
+// in-flight upload always sees a consistent client.
 type S3Client struct {
-	client     *s3.Client
-	bucketName string
+	mu  sync.Mutex
+	svc *s3.Client
+
+	bucketName        string
+	partSize          int64
+	uploadConcurrency int
+
+	cfg *config.Config
+
+	authMode       string
+	authToken      string
+	authExpiration time.Time
+
+	stopRefresh chan struct{}
+
+	metrics *metrics.Registry
+
+	clockSkew *clockSkewTracker
 }
 
-// NewS3Client creates a new S3 client with the given configuration
-func NewS3Client(cfg *config.Config) (*S3Client, error) {
-	// Create AWS configuration
+// NewS3Client creates a new S3 client with the given configuration. reg may
+// be nil, in which case the client simply isn't instrumented.
+func NewS3Client(cfg *config.Config, reg *metrics.Registry) (*S3Client, error) {
+	ctx := context.Background()
+
 	var awsConfig aws.Config
 	var err error
+	var initialCreds aws.Credentials
 
 	// Check if we're using a custom S3 endpoint (e.g., MinIO)
 	if cfg.AWSEndpointURL != "" {
-		// Custom options for local development with MinIO
+		// Custom options for local development with MinIO. Local dev always
+		// uses the static access key/secret pair, regardless of
+		// AWSAuthMode, since MinIO doesn't speak IMDS or STS federation.
 		customResolver := aws.EndpointResolverWithOptionsFunc(func(service, region string, options ...interface{}) (aws.Endpoint, error) {
 			return aws.Endpoint{
 				URL:               cfg.AWSEndpointURL,
@@ -40,7 +145,7 @@ func NewS3Client(cfg *config.Config) (*S3Client, error) {
 			}, nil
 		})
 
-		awsConfig, err = awsconfig.LoadDefaultConfig(context.Background(),
+		awsConfig, err = awsconfig.LoadDefaultConfig(ctx,
 			awsconfig.WithRegion(cfg.AWSRegion),
 			awsconfig.WithEndpointResolverWithOptions(customResolver),
 			awsconfig.WithCredentialsProvider(credentials.NewStaticCredentialsProvider(
@@ -49,24 +154,210 @@ func NewS3Client(cfg *config.Config) (*S3Client, error) {
 				"",
 			)),
 		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load AWS config: %w", err)
+		}
+		initialCreds, err = awsConfig.Credentials.Retrieve(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to retrieve AWS credentials: %w", err)
+		}
 	} else {
-		// Standard AWS configuration
-		awsConfig, err = awsconfig.LoadDefaultConfig(context.Background(),
+		provider, provErr := buildCredentialsProvider(ctx, cfg)
+		if provErr != nil {
+			return nil, fmt.Errorf("failed to build AWS credentials provider: %w", provErr)
+		}
+		initialCreds, err = provider.Retrieve(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to retrieve AWS credentials: %w", err)
+		}
+
+		// Pin the client to this snapshot rather than the live provider, so
+		// refreshing is entirely driven by our own background loop below
+		// and the reported AuthExpiration always matches the client in use.
+		awsConfig, err = awsconfig.LoadDefaultConfig(ctx,
 			awsconfig.WithRegion(cfg.AWSRegion),
+			awsconfig.WithCredentialsProvider(credentials.NewStaticCredentialsProvider(
+				initialCreds.AccessKeyID,
+				initialCreds.SecretAccessKey,
+				initialCreds.SessionToken,
+			)),
 		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load AWS config: %w", err)
+		}
+	}
+
+	partSize := cfg.S3UploadPartSize
+	if partSize <= 0 {
+		partSize = defaultPartSize
+	}
+	uploadConcurrency := cfg.S3UploadConcurrency
+	if uploadConcurrency <= 0 {
+		uploadConcurrency = defaultUploadConcurrency
 	}
 
+	clockSkew := &clockSkewTracker{}
+	svc := s3.NewFromConfig(awsConfig, func(o *s3.Options) {
+		o.APIOptions = append(o.APIOptions, func(stack *smithymiddleware.Stack) error {
+			return stack.Deserialize.Add(observeServerDateMiddleware(clockSkew), smithymiddleware.After)
+		})
+	})
+
+	c := &S3Client{
+		svc:               svc,
+		bucketName:        cfg.AWSBucketName,
+		partSize:          partSize,
+		uploadConcurrency: uploadConcurrency,
+		cfg:               cfg,
+		authMode:          strings.ToLower(cfg.AWSAuthMode),
+		authToken:         initialCreds.SessionToken,
+		authExpiration:    initialCreds.Expires,
+		metrics:           reg,
+		clockSkew:         clockSkew,
+	}
+
+	if initialCreds.CanExpire {
+		c.stopRefresh = make(chan struct{})
+		go c.refreshLoop()
+	}
+
+	if err := c.checkInitialClockSkew(ctx, cfg.MaxClockSkew, cfg.AllowSkewedClock); err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// checkInitialClockSkew issues a lightweight HeadBucket to measure the
+// delta between this machine's clock and S3's before any upload is
+// attempted. If the measured skew exceeds maxSkew, it either fails fast
+// with a clear error (the default) or, if allowSkewed is set, logs a
+// prominent warning and proceeds anyway - the SDK still signs with the
+// local clock, so this is a "run degraded and let the operator know" path,
+// not a fix for the skew itself; ClockSkew() remains available so it can be
+// surfaced on a status endpoint.
+func (c *S3Client) checkInitialClockSkew(ctx context.Context, maxSkew time.Duration, allowSkewed bool) error {
+	_, err := c.svc.HeadBucket(ctx, &s3.HeadBucketInput{Bucket: aws.String(c.bucketName)})
 	if err != nil {
-		return nil, fmt.Errorf("failed to load AWS config: %w", err)
+		// A missing bucket or auth failure here isn't this check's concern;
+		// UploadObject will surface it properly on the first real request.
+		log.Printf("WARNING: clock-skew probe HeadBucket failed, skipping skew check: %v", err)
+		return nil
+	}
+
+	skew := c.clockSkew.current()
+	if skew < 0 {
+		skew = -skew
+	}
+	if skew <= maxSkew {
+		return nil
+	}
+
+	if !allowSkewed {
+		return fmt.Errorf("local clock is %s off from S3's clock (max allowed %s); set ALLOW_SKEWED_CLOCK=true to run anyway, or fix NTP", skew, maxSkew)
 	}
 
-	// Create S3 client
-	client := s3.NewFromConfig(awsConfig)
+	log.Printf("WARNING: local clock is %s off from S3's clock (max allowed %s); ALLOW_SKEWED_CLOCK is set, so continuing; requests are still signed with the local clock", skew, maxSkew)
+	return nil
+}
 
-	return &S3Client{
-		client:     client,
-		bucketName: cfg.AWSBucketName,
-	}, nil
+// ClockSkew returns the most recently measured delta between this
+// machine's clock and S3's clock (serverTime - localTime), for the
+// worker-status HTTP endpoints. It's updated from the Date header of every
+// S3 response, so it reflects drift discovered during normal traffic, not
+// just the startup check in NewS3Client. Zero until the first request
+// completes.
+func (c *S3Client) ClockSkew() time.Duration {
+	return c.clockSkew.current()
+}
+
+// client returns the currently active S3 client, taking the refresher's
+// lock so callers never observe a client mid-swap.
+func (c *S3Client) client() *s3.Client {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.svc
+}
+
+// refreshLoop rebuilds and swaps in a freshly signed S3 client shortly
+// before the active credentials expire. It only runs when NewS3Client
+// determined the initial credentials are expiring (iam/web-identity auth).
+func (c *S3Client) refreshLoop() {
+	for {
+		c.mu.Lock()
+		expiration := c.authExpiration
+		c.mu.Unlock()
+
+		wait := time.Until(expiration) - credentialRefreshBuffer
+		if wait < 0 {
+			wait = 0
+		}
+
+		select {
+		case <-time.After(wait):
+		case <-c.stopRefresh:
+			return
+		}
+
+		if err := c.refreshCredentials(); err != nil {
+			log.Printf("Failed to refresh AWS credentials (mode=%s): %v, retrying in %s", c.authMode, err, credentialRefreshRetryInterval)
+			select {
+			case <-time.After(credentialRefreshRetryInterval):
+			case <-c.stopRefresh:
+				return
+			}
+		}
+	}
+}
+
+// refreshCredentials retrieves a new set of temporary credentials and
+// atomically swaps in a new S3 client built from them.
+func (c *S3Client) refreshCredentials() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	provider, err := buildCredentialsProvider(ctx, c.cfg)
+	if err != nil {
+		return fmt.Errorf("failed to build AWS credentials provider: %w", err)
+	}
+	creds, err := provider.Retrieve(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to retrieve AWS credentials: %w", err)
+	}
+
+	awsConfig, err := awsconfig.LoadDefaultConfig(ctx,
+		awsconfig.WithRegion(c.cfg.AWSRegion),
+		awsconfig.WithCredentialsProvider(credentials.NewStaticCredentialsProvider(
+			creds.AccessKeyID,
+			creds.SecretAccessKey,
+			creds.SessionToken,
+		)),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to load AWS config: %w", err)
+	}
+	newSvc := s3.NewFromConfig(awsConfig, func(o *s3.Options) {
+		o.APIOptions = append(o.APIOptions, func(stack *smithymiddleware.Stack) error {
+			return stack.Deserialize.Add(observeServerDateMiddleware(c.clockSkew), smithymiddleware.After)
+		})
+	})
+
+	c.mu.Lock()
+	c.svc = newSvc
+	c.authToken = creds.SessionToken
+	c.authExpiration = creds.Expires
+	c.mu.Unlock()
+
+	log.Printf("Refreshed AWS credentials (mode=%s), new expiration %s", c.authMode, creds.Expires.Format(time.RFC3339))
+	return nil
+}
+
+// Close stops the background credential refresher, if one is running. It
+// is safe to call on a client constructed with static credentials.
+func (c *S3Client) Close() {
+	if c.stopRefresh != nil {
+		close(c.stopRefresh)
+	}
 }
 
 // BucketName returns the name of the bucket used by this client
@@ -74,33 +365,231 @@ func (c *S3Client) BucketName() string {
 	return c.bucketName
 }
 
-// UploadObject uploads an object to S3 and returns the ETag (MD5 hash)
-func (c *S3Client) UploadObject(ctx context.Context, key string, reader io.Reader) (string, error) {
-	// Convert reader to byte array
-	buf := new(bytes.Buffer)
-	_, err := buf.ReadFrom(reader)
+// AuthMode returns the AWSAuthMode this client was configured with
+// ("static", "iam", or "web-identity").
+func (c *S3Client) AuthMode() string {
+	return c.authMode
+}
+
+// AuthExpiration returns when the currently active credentials expire, or
+// the zero time for static credentials, which never expire.
+func (c *S3Client) AuthExpiration() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.authExpiration
+}
+
+// PartSize returns the size this client splits multipart uploads into, so
+// a caller resuming an interrupted upload can compute which byte offset to
+// resume downloading the source from (offset = PartSize() * completed
+// part count).
+func (c *S3Client) PartSize() int64 {
+	if c.partSize <= 0 {
+		return defaultPartSize
+	}
+	return c.partSize
+}
+
+// ListMultipartUploads lists every multipart upload currently in progress
+// against this client's bucket, for transfer.ReconcileStaleUploads.
+func (c *S3Client) ListMultipartUploads(ctx context.Context) ([]MultipartUploadInfo, error) {
+	var uploads []MultipartUploadInfo
+	paginator := s3.NewListMultipartUploadsPaginator(c.client(), &s3.ListMultipartUploadsInput{
+		Bucket: aws.String(c.bucketName),
+	})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		c.metrics.ObserveS3APICall("ListMultipartUploads", c.bucketName, err)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list multipart uploads: %w", err)
+		}
+		for _, u := range page.Uploads {
+			info := MultipartUploadInfo{}
+			if u.Key != nil {
+				info.Key = *u.Key
+			}
+			if u.UploadId != nil {
+				info.UploadID = *u.UploadId
+			}
+			if u.Initiated != nil {
+				info.Initiated = *u.Initiated
+			}
+			uploads = append(uploads, info)
+		}
+	}
+	return uploads, nil
+}
+
+// AbortMultipartUpload aborts an in-progress multipart upload, for
+// transfer.ReconcileStaleUploads to clean up uploads too old to resume.
+func (c *S3Client) AbortMultipartUpload(ctx context.Context, key, uploadID string) error {
+	_, err := c.client().AbortMultipartUpload(ctx, &s3.AbortMultipartUploadInput{
+		Bucket:   aws.String(c.bucketName),
+		Key:      aws.String(key),
+		UploadId: aws.String(uploadID),
+	})
+	c.metrics.ObserveS3APICall("AbortMultipartUpload", c.bucketName, err)
 	if err != nil {
-		return "", fmt.Errorf("failed to read data: %w", err)
+		return fmt.Errorf("failed to abort multipart upload: %w", err)
 	}
+	return nil
+}
+
+// UploadObject uploads an object to S3, enforcing server-side integrity
+// verification via the Content-MD5 header and the x-amz-checksum-sha256
+// trailing checksum rather than trusting a 200 response alone.
+//
+// reader is consumed in bounded c.partSize chunks rather than being buffered
+// in full: payloads that fit in a single part go through a plain PutObject,
+// and anything larger is streamed through a concurrent multipart upload (up
+// to c.uploadConcurrency parts in flight at once) so large USV telemetry
+// blobs never land in memory all at once.
+//
+// expectedMD5Hex, if non-empty, is the hex-encoded MD5 the caller already
+// computed for this data (e.g. from upstream blob metadata); it is checked
+// against the locally recalculated hash before the object is committed, and
+// (for single-part uploads) supplied to S3 as the Content-MD5 header so S3
+// itself rejects a corrupted upload.
+//
+// It returns the S3 ETag (for multipart uploads, the composite
+// "md5(concat(part_md5s))-N" ETag S3 itself reports) and the base64 SHA256
+// checksum computed over the object.
+//
+// destMetadata, if non-nil, is attached to the object as S3 user metadata
+// (the AWS SDK sends it as the x-amz-meta-* headers); it's the caller's
+// responsibility to have already translated key names into the form S3
+// expects (see transfer/metadata.MetadataMapper).
+func (c *S3Client) UploadObject(ctx context.Context, key string, reader io.Reader, expectedMD5Hex string, destMetadata map[string]string) (string, string, error) {
+	return c.uploadObject(ctx, key, reader, expectedMD5Hex, destMetadata, nil)
+}
+
+// UploadObjectResumable behaves like UploadObject, but threads checkpoint
+// through to the multipart path so an upload interrupted partway through
+// can resume from its last completed part instead of starting over.
+// checkpoint is only consulted for multipart uploads; a payload that fits
+// in a single part has nothing to resume. If checkpoint.Resume reports an
+// upload already in progress, reader must already be positioned at the
+// byte offset that upload's completed parts imply (completed-part-count *
+// PartSize()) — it is the caller's responsibility to re-download from
+// there, since this client has no way to seek its own input. destMetadata
+// is ignored when resuming: it was already sent with the CreateMultipartUpload
+// call that started the upload being resumed.
+func (c *S3Client) UploadObjectResumable(ctx context.Context, key string, reader io.Reader, expectedMD5Hex string, destMetadata map[string]string, checkpoint UploadCheckpoint) (string, string, error) {
+	return c.uploadObject(ctx, key, reader, expectedMD5Hex, destMetadata, checkpoint)
+}
 
-	// Get the data size
-	data := buf.Bytes()
+func (c *S3Client) uploadObject(ctx context.Context, key string, reader io.Reader, expectedMD5Hex string, destMetadata map[string]string, checkpoint UploadCheckpoint) (string, string, error) {
+	start := time.Now()
+	var bytesSent int64
+	var err error
+	defer func() {
+		c.metrics.ObserveBlobOp("put", c.bucketName, "", bytesSent, time.Since(start), err)
+	}()
+
+	partSize := c.PartSize()
+
+	var resumeUploadID string
+	var resumeParts []CompletedUploadPart
+	if checkpoint != nil {
+		resumeUploadID, resumeParts = checkpoint.Resume()
+	}
+
+	if resumeUploadID != "" {
+		// The multipart upload already exists server-side, so every
+		// remaining byte - however little - must go through UploadPart and
+		// CompleteMultipartUpload; falling through to a plain PutObject
+		// below would leave that upload dangling and create an unrelated
+		// second object.
+		var etag, sha256Checksum string
+		etag, sha256Checksum, err = c.uploadMultipart(ctx, key, reader, expectedMD5Hex, destMetadata, partSize, checkpoint, resumeUploadID, resumeParts)
+		return etag, sha256Checksum, err
+	}
+
+	firstPart := make([]byte, partSize)
+	n, readErr := io.ReadFull(reader, firstPart)
+	if readErr != nil && readErr != io.ErrUnexpectedEOF && readErr != io.EOF {
+		err = fmt.Errorf("failed to read data: %w", readErr)
+		return "", "", err
+	}
+	firstPart = firstPart[:n]
+	bytesSent = int64(n)
+
+	// Peek a single extra byte to find out whether there is a second part
+	// without reading the rest of the stream into memory.
+	var lookahead [1]byte
+	m, readErr := io.ReadFull(reader, lookahead[:])
+	if readErr != nil && readErr != io.ErrUnexpectedEOF && readErr != io.EOF {
+		err = fmt.Errorf("failed to read data: %w", readErr)
+		return "", "", err
+	}
+
+	if m == 0 {
+		var etag, sha256Checksum string
+		etag, sha256Checksum, err = c.uploadSinglePart(ctx, key, firstPart, expectedMD5Hex, destMetadata)
+		return etag, sha256Checksum, err
+	}
+
+	counted := &byteCountingReader{r: io.MultiReader(bytes.NewReader(firstPart), bytes.NewReader(lookahead[:m]), reader)}
+	var etag, sha256Checksum string
+	etag, sha256Checksum, err = c.uploadMultipart(ctx, key, counted, expectedMD5Hex, destMetadata, partSize, checkpoint, "", nil)
+	bytesSent = counted.n
+	return etag, sha256Checksum, err
+}
+
+// byteCountingReader wraps an io.Reader, tracking the total number of bytes
+// read so UploadObject can report blob_bytes_total for the multipart path
+// without uploadMultipart itself needing to know about metrics.
+type byteCountingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *byteCountingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// uploadSinglePart uploads data that fit entirely within one part via a
+// plain PutObject.
+func (c *S3Client) uploadSinglePart(ctx context.Context, key string, data []byte, expectedMD5Hex string, destMetadata map[string]string) (string, string, error) {
 	dataSize := len(data)
 	log.Printf("Preparing to upload %d bytes to S3: %s/%s", dataSize, c.bucketName, key)
 
-	// Calculate MD5 hash locally before upload
-	md5Hash := md5.Sum(data)
-	calculatedMD5 := hex.EncodeToString(md5Hash[:])
-	log.Printf("Calculated MD5 hash for %s: %s", key, calculatedMD5)
+	// Calculate MD5 and SHA256 hashes locally before upload
+	md5Sum := md5.Sum(data)
+	calculatedMD5 := hex.EncodeToString(md5Sum[:])
+	sha256Sum := sha256.Sum256(data)
+	calculatedSHA256 := base64.StdEncoding.EncodeToString(sha256Sum[:])
+	log.Printf("Calculated checksums for %s: md5=%s sha256=%s", key, calculatedMD5, calculatedSHA256)
+
+	// If the caller already knows the expected MD5 (e.g. from the source
+	// system's own checksum sidecar), catch corruption that happened before
+	// this upload ever started rather than discovering it only via S3.
+	if expectedMD5Hex != "" && !strings.EqualFold(expectedMD5Hex, calculatedMD5) {
+		return "", "", fmt.Errorf("data corrupted before upload: expected MD5 %s, recalculated %s", expectedMD5Hex, calculatedMD5)
+	}
+
+	input := &s3.PutObjectInput{
+		Bucket:            aws.String(c.bucketName),
+		Key:               aws.String(key),
+		Body:              bytes.NewReader(data),
+		ChecksumAlgorithm: types.ChecksumAlgorithmSha256,
+		Metadata:          destMetadata,
+	}
+	if expectedMD5Hex != "" {
+		md5Bytes, decodeErr := hex.DecodeString(expectedMD5Hex)
+		if decodeErr != nil {
+			return "", "", fmt.Errorf("invalid expected MD5 %q: %w", expectedMD5Hex, decodeErr)
+		}
+		input.ContentMD5 = aws.String(base64.StdEncoding.EncodeToString(md5Bytes))
+	}
 
 	// Upload the object
-	response, err := c.client.PutObject(ctx, &s3.PutObjectInput{
-		Bucket: aws.String(c.bucketName),
-		Key:    aws.String(key),
-		Body:   bytes.NewReader(data),
-	})
+	response, err := c.client().PutObject(ctx, input)
+	c.metrics.ObserveS3APICall("PutObject", c.bucketName, err)
 	if err != nil {
-		return "", fmt.Errorf("failed to upload object: %w", err)
+		return "", "", fmt.Errorf("failed to upload object: %w", err)
 	}
 
 	// Extract ETag (MD5 hash) from response
@@ -111,65 +600,483 @@ func (c *S3Client) UploadObject(ctx context.Context, key string, reader io.Reade
 		log.Printf("Received ETag from S3 for %s: %s", key, eTag)
 
 		// Compare with calculated MD5
-		if strings.EqualFold(eTag, calculatedMD5) {
-			log.Printf("MD5 verification succeeded for %s: calculated=%s, S3=%s", key, calculatedMD5, eTag)
-		} else {
-			log.Printf("WARNING: MD5 mismatch for %s: calculated=%s, S3=%s", key, calculatedMD5, eTag)
+		if !strings.EqualFold(eTag, calculatedMD5) {
+			return eTag, "", fmt.Errorf("ETag mismatch for %s: calculated MD5 %s, S3 ETag %s", key, calculatedMD5, eTag)
 		}
+		log.Printf("MD5 verification succeeded for %s: calculated=%s, S3=%s", key, calculatedMD5, eTag)
 	} else {
 		log.Printf("WARNING: No ETag received from S3 for %s", key)
 	}
 
+	// Compare the SHA256 checksum S3 computed server-side against what we
+	// computed locally before sending the bytes.
+	var sha256Checksum string
+	if response.ChecksumSHA256 != nil {
+		sha256Checksum = *response.ChecksumSHA256
+		if sha256Checksum != calculatedSHA256 {
+			return eTag, "", fmt.Errorf("SHA256 checksum mismatch for %s: calculated %s, S3 %s", key, calculatedSHA256, sha256Checksum)
+		}
+		log.Printf("SHA256 verification succeeded for %s: %s", key, sha256Checksum)
+	} else {
+		log.Printf("WARNING: No SHA256 checksum received from S3 for %s", key)
+	}
+
 	// Verify the object was uploaded correctly
 	log.Printf("Upload complete, verifying object in S3: %s/%s", c.bucketName, key)
-	exists, err := c.VerifyObject(ctx, key)
+	exists, err := c.VerifyObject(ctx, key, sha256Checksum)
 	if err != nil {
-		return eTag, fmt.Errorf("upload succeeded but verification failed: %w", err)
+		return eTag, sha256Checksum, fmt.Errorf("upload succeeded but verification failed: %w", err)
 	}
 	if !exists {
-		return eTag, fmt.Errorf("upload appeared to succeed but object not found in S3")
+		return eTag, sha256Checksum, fmt.Errorf("upload appeared to succeed but object not found in S3")
 	}
 
 	log.Printf("Successfully uploaded and verified object in S3: %s/%s (%d bytes)", c.bucketName, key, dataSize)
-	return eTag, nil
+	return eTag, sha256Checksum, nil
+}
+
+// completedPartResult is the outcome of uploading a single part, carrying
+// enough information to validate and assemble the composite ETag once every
+// part has finished.
+type completedPartResult struct {
+	part types.CompletedPart
+	md5  [md5.Size]byte
+}
+
+// CompletedUploadPart records one already-uploaded part of a resumable
+// multipart upload: just enough to skip re-uploading it and to fold it back
+// into the composite ETag S3 will report once the upload completes.
+type CompletedUploadPart struct {
+	PartNumber int32
+	ETag       string
+	MD5Hex     string
 }
 
-// VerifyObject verifies that an object exists in S3
-func (c *S3Client) VerifyObject(ctx context.Context, key string) (bool, error) {
+// UploadCheckpoint lets a caller persist and resume the state of an
+// in-progress multipart upload across process restarts or transient
+// failures, so a transfer interrupted partway through (e.g. by a dropped
+// satellite link) resumes from its last completed part instead of
+// re-uploading the whole object. UploadObjectResumable calls Resume once up
+// front and PartCompleted synchronously after every part succeeds, so the
+// checkpoint is never behind what's actually landed in S3.
+type UploadCheckpoint interface {
+	// Resume returns a previously persisted upload ID and its completed
+	// parts in part-number order, or ("", nil) to start a fresh upload.
+	Resume() (uploadID string, parts []CompletedUploadPart)
+
+	// PartCompleted persists that part has been uploaded to uploadID.
+	PartCompleted(uploadID string, part CompletedUploadPart) error
+}
+
+// MultipartUploadInfo describes one multipart upload currently in progress
+// against a bucket, as reported by ListMultipartUploads, for
+// transfer.ReconcileStaleUploads to match against a blob's persisted
+// uploadid and decide whether it's stale.
+type MultipartUploadInfo struct {
+	Key       string
+	UploadID  string
+	Initiated time.Time
+}
+
+// uploadMultipart streams data larger than one part through S3's multipart
+// upload API, uploading up to c.uploadConcurrency parts concurrently. It
+// computes the composite ETag and a whole-object SHA256 locally while
+// streaming so the source is never re-read or buffered in full.
+//
+// When resumeUploadID is non-empty, it reuses that already-open upload
+// instead of creating a new one and seeds results with resumeParts, so the
+// parts they cover are neither re-read from src nor re-uploaded. Resuming
+// means the whole-object MD5/SHA256 can no longer be computed (the bytes
+// behind already-completed parts were never re-read), so both are skipped
+// with a logged warning; the composite ETag is still recomputed and
+// verified across every part, resumed or new, which is what actually
+// proves the final object matches what was uploaded.
+func (c *S3Client) uploadMultipart(ctx context.Context, key string, src io.Reader, expectedMD5Hex string, destMetadata map[string]string, partSize int64, checkpoint UploadCheckpoint, resumeUploadID string, resumeParts []CompletedUploadPart) (string, string, error) {
+	resuming := resumeUploadID != ""
+
+	var uploadID *string
+	if resuming {
+		log.Printf("Resuming multipart upload %s for %s/%s at part %d", resumeUploadID, c.bucketName, key, len(resumeParts)+1)
+		uploadID = aws.String(resumeUploadID)
+	} else {
+		log.Printf("Upload exceeds part size, starting multipart upload to S3: %s/%s", c.bucketName, key)
+
+		createResp, err := c.client().CreateMultipartUpload(ctx, &s3.CreateMultipartUploadInput{
+			Bucket:            aws.String(c.bucketName),
+			Key:               aws.String(key),
+			ChecksumAlgorithm: types.ChecksumAlgorithmSha256,
+			Metadata:          destMetadata,
+		})
+		c.metrics.ObserveS3APICall("CreateMultipartUpload", c.bucketName, err)
+		if err != nil {
+			return "", "", fmt.Errorf("failed to create multipart upload: %w", err)
+		}
+		uploadID = createResp.UploadId
+	}
+
+	abort := func() {
+		_, abortErr := c.client().AbortMultipartUpload(ctx, &s3.AbortMultipartUploadInput{
+			Bucket:   aws.String(c.bucketName),
+			Key:      aws.String(key),
+			UploadId: uploadID,
+		})
+		c.metrics.ObserveS3APICall("AbortMultipartUpload", c.bucketName, abortErr)
+		if abortErr != nil {
+			log.Printf("Failed to abort multipart upload %s for %s: %v", aws.ToString(uploadID), key, abortErr)
+		}
+	}
+
+	var overallMD5, overallSHA256 hash.Hash
+	if !resuming {
+		overallMD5 = md5.New()
+		overallSHA256 = sha256.New()
+	}
+
+	sem := make(chan struct{}, c.uploadConcurrency)
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	var results []completedPartResult
+	var firstErr error
+
+	for _, p := range resumeParts {
+		etagBytes, decodeErr := hex.DecodeString(p.MD5Hex)
+		if decodeErr != nil || len(etagBytes) != md5.Size {
+			return "", "", fmt.Errorf("invalid checkpointed MD5 for part %d of %s: %q", p.PartNumber, key, p.MD5Hex)
+		}
+		var md5Arr [md5.Size]byte
+		copy(md5Arr[:], etagBytes)
+		results = append(results, completedPartResult{
+			part: types.CompletedPart{
+				ETag:       aws.String(p.ETag),
+				PartNumber: aws.Int32(p.PartNumber),
+			},
+			md5: md5Arr,
+		})
+	}
+
+	uploadPart := func(partNumber int32, chunk []byte) {
+		defer wg.Done()
+		defer func() { <-sem }()
+
+		chunkMD5 := md5.Sum(chunk)
+		chunkSHA256 := sha256.Sum256(chunk)
+		expectedChunkSHA256 := base64.StdEncoding.EncodeToString(chunkSHA256[:])
+
+		partResp, partErr := c.client().UploadPart(ctx, &s3.UploadPartInput{
+			Bucket:            aws.String(c.bucketName),
+			Key:               aws.String(key),
+			UploadId:          uploadID,
+			PartNumber:        aws.Int32(partNumber),
+			Body:              bytes.NewReader(chunk),
+			ContentMD5:        aws.String(base64.StdEncoding.EncodeToString(chunkMD5[:])),
+			ChecksumAlgorithm: types.ChecksumAlgorithmSha256,
+		})
+		c.metrics.ObserveS3APICall("UploadPart", c.bucketName, partErr)
+
+		mu.Lock()
+		defer mu.Unlock()
+		if firstErr != nil {
+			return
+		}
+		if partErr != nil {
+			firstErr = fmt.Errorf("failed to upload part %d: %w", partNumber, partErr)
+			return
+		}
+		if partResp.ETag == nil {
+			firstErr = fmt.Errorf("no ETag returned for part %d", partNumber)
+			return
+		}
+		if partResp.ChecksumSHA256 != nil && *partResp.ChecksumSHA256 != expectedChunkSHA256 {
+			firstErr = fmt.Errorf("SHA256 checksum mismatch for part %d of %s: calculated %s, S3 %s", partNumber, key, expectedChunkSHA256, *partResp.ChecksumSHA256)
+			return
+		}
+
+		results = append(results, completedPartResult{
+			part: types.CompletedPart{
+				ETag:           partResp.ETag,
+				PartNumber:     aws.Int32(partNumber),
+				ChecksumSHA256: partResp.ChecksumSHA256,
+			},
+			md5: chunkMD5,
+		})
+
+		if checkpoint != nil {
+			if ckErr := checkpoint.PartCompleted(aws.ToString(uploadID), CompletedUploadPart{
+				PartNumber: partNumber,
+				ETag:       aws.ToString(partResp.ETag),
+				MD5Hex:     hex.EncodeToString(chunkMD5[:]),
+			}); ckErr != nil {
+				log.Printf("Warning: failed to persist upload checkpoint for part %d of %s: %v", partNumber, key, ckErr)
+			}
+		}
+	}
+
+	partNumber := int32(len(resumeParts) + 1)
+	for {
+		chunk := make([]byte, partSize)
+		readN, readErr := io.ReadFull(src, chunk)
+		if readErr != nil && readErr != io.ErrUnexpectedEOF && readErr != io.EOF {
+			wg.Wait()
+			abort()
+			return "", "", fmt.Errorf("failed to read part %d: %w", partNumber, readErr)
+		}
+		last := readErr == io.ErrUnexpectedEOF || readErr == io.EOF
+		chunk = chunk[:readN]
+		if len(chunk) == 0 {
+			break
+		}
+
+		if overallMD5 != nil {
+			overallMD5.Write(chunk)
+			overallSHA256.Write(chunk)
+		}
+
+		sem <- struct{}{}
+		wg.Add(1)
+		go uploadPart(partNumber, chunk)
+		partNumber++
+
+		if last {
+			break
+		}
+	}
+
+	wg.Wait()
+
+	if firstErr != nil {
+		abort()
+		return "", "", firstErr
+	}
+
+	// Catch source corruption before committing the object, even though the
+	// parts are already uploaded: the object is only finalized by
+	// CompleteMultipartUpload below, so aborting here still leaves S3 clean.
+	// Resumed uploads never re-read the bytes behind their already-completed
+	// parts, so there's no whole-object hash to check here; that's logged
+	// once, below, rather than attempted.
+	if overallMD5 != nil {
+		calculatedMD5 := hex.EncodeToString(overallMD5.Sum(nil))
+		if expectedMD5Hex != "" && !strings.EqualFold(expectedMD5Hex, calculatedMD5) {
+			abort()
+			return "", "", fmt.Errorf("data corrupted before upload: expected MD5 %s, recalculated %s", expectedMD5Hex, calculatedMD5)
+		}
+	} else {
+		log.Printf("Resumed upload for %s: skipping whole-object MD5 check, relying on composite ETag verification", key)
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		return *results[i].part.PartNumber < *results[j].part.PartNumber
+	})
+
+	completedParts := make([]types.CompletedPart, len(results))
+	concatenatedMD5s := make([]byte, 0, len(results)*md5.Size)
+	for i, r := range results {
+		completedParts[i] = r.part
+		concatenatedMD5s = append(concatenatedMD5s, r.md5[:]...)
+	}
+
+	completeResp, err := c.client().CompleteMultipartUpload(ctx, &s3.CompleteMultipartUploadInput{
+		Bucket:          aws.String(c.bucketName),
+		Key:             aws.String(key),
+		UploadId:        uploadID,
+		MultipartUpload: &types.CompletedMultipartUpload{Parts: completedParts},
+	})
+	c.metrics.ObserveS3APICall("CompleteMultipartUpload", c.bucketName, err)
+	if err != nil {
+		abort()
+		return "", "", fmt.Errorf("failed to complete multipart upload: %w", err)
+	}
+
+	// S3's ETag for a multipart object is md5(concat(part MD5s))-N, not a
+	// plain MD5 of the object; recompute it locally and compare so a part
+	// silently dropped or reordered server-side is still caught.
+	compositeSum := md5.Sum(concatenatedMD5s)
+	compositeETag := fmt.Sprintf("%s-%d", hex.EncodeToString(compositeSum[:]), len(results))
+
+	var eTag string
+	if completeResp.ETag != nil {
+		eTag = strings.Trim(*completeResp.ETag, "\"")
+		if eTag != compositeETag {
+			return eTag, "", fmt.Errorf("composite ETag mismatch for %s: calculated %s, S3 %s", key, compositeETag, eTag)
+		}
+		log.Printf("Composite ETag verification succeeded for %s: %s", key, eTag)
+	} else {
+		log.Printf("WARNING: No ETag received from S3 for %s", key)
+	}
+
+	var sha256Checksum string
+	if overallSHA256 != nil {
+		sha256Checksum = base64.StdEncoding.EncodeToString(overallSHA256.Sum(nil))
+	}
+
+	log.Printf("Upload complete, verifying object in S3: %s/%s", c.bucketName, key)
+	exists, err := c.VerifyObject(ctx, key, sha256Checksum)
+	if err != nil {
+		return eTag, sha256Checksum, fmt.Errorf("upload succeeded but verification failed: %w", err)
+	}
+	if !exists {
+		return eTag, sha256Checksum, fmt.Errorf("upload appeared to succeed but object not found in S3")
+	}
+
+	log.Printf("Successfully uploaded and verified %d-part object in S3: %s/%s", len(results), c.bucketName, key)
+	return eTag, sha256Checksum, nil
+}
+
+// VerifyObject verifies that an object exists in S3. If expectedSHA256 is
+// non-empty, it also re-requests S3's server-side SHA256 checksum via
+// HeadObject with ChecksumMode ENABLED and compares it against
+// expectedSHA256: PutObject/CompleteMultipartUpload's own response already
+// echoes a checksum, but that only proves what S3 received in that one
+// response, not what a HeadObject moments later actually reads back. A
+// mismatch here wraps ErrChecksumMismatch so callers can tell it apart from
+// an ordinary verification failure (e.g. the object not existing at all).
+func (c *S3Client) VerifyObject(ctx context.Context, key, expectedSHA256 string) (bool, error) {
 	log.Printf("Verifying object exists in S3: %s/%s", c.bucketName, key)
-	
-	// Check if the object exists
-	resp, err := c.client.HeadObject(ctx, &s3.HeadObjectInput{
+
+	input := &s3.HeadObjectInput{
 		Bucket: aws.String(c.bucketName),
 		Key:    aws.String(key),
-	})
-	
+	}
+	if expectedSHA256 != "" {
+		input.ChecksumMode = types.ChecksumModeEnabled
+	}
+
+	// Check if the object exists
+	resp, err := c.client().HeadObject(ctx, input)
+	c.metrics.ObserveS3APICall("HeadObject", c.bucketName, err)
+
 	// Handle specific error types
 	if err != nil {
 		// For now, just check if the error string contains "not found" or "not exist"
 		// This is a simplification since the exact error type can vary between S3 implementations
 		errStr := err.Error()
-		if strings.Contains(strings.ToLower(errStr), "not found") || 
+		if strings.Contains(strings.ToLower(errStr), "not found") ||
 		   strings.Contains(strings.ToLower(errStr), "not exist") ||
 		   strings.Contains(strings.ToLower(errStr), "no such key") {
 			log.Printf("Object not found in S3: %s/%s", c.bucketName, key)
 			return false, nil
 		}
-		
+
 		// Unknown error
 		return false, fmt.Errorf("failed to verify object: %w", err)
 	}
-	
+
 	// Object exists, log some details
 	contentLength := int64(0)
 	if resp.ContentLength != nil {
 		contentLength = *resp.ContentLength
 	}
-	
+
+	if expectedSHA256 != "" {
+		var gotSHA256 string
+		if resp.ChecksumSHA256 != nil {
+			gotSHA256 = *resp.ChecksumSHA256
+		}
+		if gotSHA256 != expectedSHA256 {
+			return true, fmt.Errorf("%w: %s: expected %s, HeadObject reports %q", ErrChecksumMismatch, key, expectedSHA256, gotSHA256)
+		}
+		log.Printf("Re-verified SHA256 checksum for %s via HeadObject: %s", key, gotSHA256)
+	}
+
 	log.Printf("Object verified in S3: %s/%s (size: %d bytes)", c.bucketName, key, contentLength)
 	return true, nil
 }
 
+// StatObject reports whether an object exists in S3 and, if so, its size.
+// It's distinct from VerifyObject (which only the existing integrity-check
+// callers use) so the storage.Volume wrapper can satisfy Volume.Head without
+// also having to parse VerifyObject's bool-only result for a size.
+func (c *S3Client) StatObject(ctx context.Context, key string) (bool, int64, error) {
+	start := time.Now()
+	resp, err := c.client().HeadObject(ctx, &s3.HeadObjectInput{
+		Bucket: aws.String(c.bucketName),
+		Key:    aws.String(key),
+	})
+	c.metrics.ObserveS3APICall("HeadObject", c.bucketName, err)
+	if err != nil {
+		errStr := strings.ToLower(err.Error())
+		if strings.Contains(errStr, "not found") || strings.Contains(errStr, "not exist") || strings.Contains(errStr, "no such key") {
+			c.metrics.ObserveBlobOp("head", c.bucketName, "", 0, time.Since(start), nil)
+			return false, 0, nil
+		}
+		c.metrics.ObserveBlobOp("head", c.bucketName, "", 0, time.Since(start), err)
+		return false, 0, fmt.Errorf("failed to stat object: %w", err)
+	}
+
+	var size int64
+	if resp.ContentLength != nil {
+		size = *resp.ContentLength
+	}
+	c.metrics.ObserveBlobOp("head", c.bucketName, "", 0, time.Since(start), nil)
+	return true, size, nil
+}
+
+// GetObject opens an object in S3 for reading. Callers must close it.
+func (c *S3Client) GetObject(ctx context.Context, key string) (io.ReadCloser, error) {
+	start := time.Now()
+	resp, err := c.client().GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(c.bucketName),
+		Key:    aws.String(key),
+	})
+	c.metrics.ObserveS3APICall("GetObject", c.bucketName, err)
+	if err != nil {
+		c.metrics.ObserveBlobOp("get", c.bucketName, "", 0, time.Since(start), err)
+		return nil, fmt.Errorf("failed to get object: %w", err)
+	}
+	var bytesReceived int64
+	if resp.ContentLength != nil {
+		bytesReceived = *resp.ContentLength
+	}
+	c.metrics.ObserveBlobOp("get", c.bucketName, "", bytesReceived, time.Since(start), nil)
+	return resp.Body, nil
+}
+
+// DeleteObject removes an object from S3.
+func (c *S3Client) DeleteObject(ctx context.Context, key string) error {
+	start := time.Now()
+	_, err := c.client().DeleteObject(ctx, &s3.DeleteObjectInput{
+		Bucket: aws.String(c.bucketName),
+		Key:    aws.String(key),
+	})
+	c.metrics.ObserveS3APICall("DeleteObject", c.bucketName, err)
+	c.metrics.ObserveBlobOp("delete", c.bucketName, "", 0, time.Since(start), err)
+	if err != nil {
+		return fmt.Errorf("failed to delete object: %w", err)
+	}
+	return nil
+}
+
+// ListObjects calls cb for every object whose key starts with prefix,
+// paging through the bucket listing as needed.
+func (c *S3Client) ListObjects(ctx context.Context, prefix string, cb func(key string, mtime time.Time)) error {
+	start := time.Now()
+	paginator := s3.NewListObjectsV2Paginator(c.client(), &s3.ListObjectsV2Input{
+		Bucket: aws.String(c.bucketName),
+		Prefix: aws.String(prefix),
+	})
+
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		c.metrics.ObserveS3APICall("ListObjectsV2", c.bucketName, err)
+		if err != nil {
+			c.metrics.ObserveBlobOp("list", c.bucketName, "", 0, time.Since(start), err)
+			return fmt.Errorf("failed to list objects: %w", err)
+		}
+		for _, obj := range page.Contents {
+			if obj.Key == nil {
+				continue
+			}
+			var mtime time.Time
+			if obj.LastModified != nil {
+				mtime = *obj.LastModified
+			}
+			cb(*obj.Key, mtime)
+		}
+	}
+	c.metrics.ObserveBlobOp("list", c.bucketName, "", 0, time.Since(start), nil)
+	return nil
+}
+
 // BuildObjectKey builds a fully qualified S3 key for a blob
 // The format is: {vesselId}/data/{blobName}
 func BuildObjectKey(vesselID, blobName string) string {
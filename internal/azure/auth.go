@@ -0,0 +1,237 @@
+package azure
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/Azure/azure-sdk-for-go/sdk/security/keyvault/azsecrets"
+	"github.com/CAST-Intelligence/elysium-usv/internal/config"
+)
+
+// AzureAuthConfig describes how the Azure Storage clients should
+// authenticate. It is built from config.Config by newAuthConfig; most
+// callers should just use NewClient(cfg) and never construct this
+// directly.
+type AzureAuthConfig struct {
+	// Mode selects a single explicit auth path. Leave empty to build a
+	// ChainedTokenCredential that tries workload identity, then managed
+	// identity, then service principal, then the Azure CLI/environment
+	// defaults, in that order.
+	Mode string
+
+	AccountName string
+	AccountKey  string
+
+	// SASURL is a full blob or queue endpoint including a SAS token, e.g.
+	// "https://account.blob.core.windows.net?sv=...&sig=...".
+	SASURL string
+
+	TenantID     string
+	ClientID     string
+	ClientSecret string
+
+	// FederatedTokenFile is the path to the projected service account
+	// token used for AKS workload identity / IRSA-style federation.
+	FederatedTokenFile string
+
+	// ManagedIdentityClientID selects a specific user-assigned managed
+	// identity; leave empty to use the system-assigned identity.
+	ManagedIdentityClientID string
+
+	// KeyVaultEndpoint and ConnectionStringSecretName are used to fetch
+	// the storage connection string itself from Key Vault at startup
+	// (Mode == "key-vault").
+	KeyVaultEndpoint           string
+	ConnectionStringSecretName string
+
+	// ClientOptions is applied to every Azure SDK client this package
+	// constructs. newAuthConfig populates it from the AzureClientRetry*
+	// config fields (see clientOptionsFromConfig); it is nil, leaving the
+	// SDK's own defaults in place, unless AzureClientRetryMaxAttempts is
+	// set.
+	ClientOptions *policy.ClientOptions
+}
+
+func newAuthConfig(cfg *config.Config) AzureAuthConfig {
+	return AzureAuthConfig{
+		Mode:                       cfg.AzureAuthMode,
+		AccountName:                cfg.AzureAccountName,
+		AccountKey:                 cfg.AzureAccountKey,
+		SASURL:                     cfg.AzureSASURL,
+		TenantID:                   cfg.AzureTenantID,
+		ClientID:                   cfg.AzureClientID,
+		ClientSecret:               cfg.AzureClientSecret,
+		FederatedTokenFile:         cfg.AzureFederatedTokenFile,
+		ManagedIdentityClientID:    cfg.AzureManagedIdentityClientID,
+		KeyVaultEndpoint:           cfg.AzureKeyVaultEndpoint,
+		ConnectionStringSecretName: cfg.AzureConnectionStringSecretName,
+		ClientOptions:              clientOptionsFromConfig(cfg),
+	}
+}
+
+// clientOptionsFromConfig builds the policy.ClientOptions applied to every
+// Azure SDK client this package constructs, from the retry settings in cfg.
+// AzureClientRetryMaxAttempts defaults to 0, meaning "use the SDK's own
+// default retry policy" - in that case this returns nil rather than an
+// options struct with a zero MaxRetries, which would disable retries
+// entirely instead of leaving them at the SDK default.
+func clientOptionsFromConfig(cfg *config.Config) *policy.ClientOptions {
+	if cfg.AzureClientRetryMaxAttempts <= 0 {
+		return nil
+	}
+
+	return &policy.ClientOptions{
+		Retry: policy.RetryOptions{
+			MaxRetries: int32(cfg.AzureClientRetryMaxAttempts),
+			TryTimeout: cfg.AzureClientRetryTimeout,
+		},
+	}
+}
+
+// resolveConnectionString returns cfg's static connection string, or (when
+// AzureAuthMode is "key-vault") fetches it from Key Vault once at startup.
+func resolveConnectionString(cfg *config.Config) (string, error) {
+	if cfg.AzureAuthMode != "key-vault" {
+		return cfg.AzureStorageConnectionString, nil
+	}
+
+	if cfg.AzureKeyVaultEndpoint == "" || cfg.AzureConnectionStringSecretName == "" {
+		return "", fmt.Errorf("AZURE_KEY_VAULT_ENDPOINT and AZURE_CONNECTION_STRING_SECRET_NAME are required for key-vault auth mode")
+	}
+
+	cred, err := azidentity.NewDefaultAzureCredential(nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to create credential for Key Vault: %w", err)
+	}
+
+	client, err := azsecrets.NewClient(cfg.AzureKeyVaultEndpoint, cred, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to create Key Vault client: %w", err)
+	}
+
+	resp, err := client.GetSecret(context.Background(), cfg.AzureConnectionStringSecretName, "", nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch connection string secret: %w", err)
+	}
+	if resp.Value == nil {
+		return "", fmt.Errorf("connection string secret %q has no value", cfg.AzureConnectionStringSecretName)
+	}
+
+	return *resp.Value, nil
+}
+
+// namedCredential pairs a azcore.TokenCredential with a human-readable name
+// so loggingCredential can report which credential in the chain actually
+// served a token.
+type namedCredential struct {
+	name string
+	cred azcore.TokenCredential
+}
+
+// loggingCredential wraps a ChainedTokenCredential's sources so we can log
+// which credential succeeded on the first token request, the way the
+// request asked for ("log which credential succeeded") without needing to
+// reach into azidentity's internals.
+type loggingCredential struct {
+	name string
+	cred azcore.TokenCredential
+}
+
+func (c *loggingCredential) GetToken(ctx context.Context, options policy.TokenRequestOptions) (azcore.AccessToken, error) {
+	token, err := c.cred.GetToken(ctx, options)
+	if err != nil {
+		log.Printf("Azure credential %q failed: %v", c.name, err)
+		return token, err
+	}
+	log.Printf("Azure credential %q succeeded", c.name)
+	return token, nil
+}
+
+// buildCredential returns the azcore.TokenCredential to use for the
+// non-static auth modes, built either from an explicit Mode or, if Mode is
+// empty, from a ChainedTokenCredential over every credential type the
+// config has enough information to construct, tried in priority order:
+// workload identity, managed identity, service principal, then the
+// environment/CLI defaults.
+func buildCredential(auth AzureAuthConfig) (azcore.TokenCredential, error) {
+	switch auth.Mode {
+	case "service-principal":
+		return namedWorkloadOrServicePrincipal(auth)
+	case "workload-identity":
+		return workloadIdentityCredential(auth)
+	case "managed-identity":
+		return managedIdentityCredential(auth)
+	case "":
+		return chainedCredential(auth)
+	default:
+		return nil, fmt.Errorf("unsupported AzureAuthMode for token credential: %q", auth.Mode)
+	}
+}
+
+func namedWorkloadOrServicePrincipal(auth AzureAuthConfig) (azcore.TokenCredential, error) {
+	if auth.TenantID == "" || auth.ClientID == "" || auth.ClientSecret == "" {
+		return nil, fmt.Errorf("AZURE_TENANT_ID, AZURE_CLIENT_ID, and AZURE_CLIENT_SECRET are required for service-principal auth")
+	}
+	return azidentity.NewClientSecretCredential(auth.TenantID, auth.ClientID, auth.ClientSecret, nil)
+}
+
+func workloadIdentityCredential(auth AzureAuthConfig) (azcore.TokenCredential, error) {
+	if auth.TenantID == "" || auth.ClientID == "" || auth.FederatedTokenFile == "" {
+		return nil, fmt.Errorf("AZURE_TENANT_ID, AZURE_CLIENT_ID, and AZURE_FEDERATED_TOKEN_FILE are required for workload-identity auth")
+	}
+	return azidentity.NewWorkloadIdentityCredential(&azidentity.WorkloadIdentityCredentialOptions{
+		TenantID:      auth.TenantID,
+		ClientID:      auth.ClientID,
+		TokenFilePath: auth.FederatedTokenFile,
+	})
+}
+
+func managedIdentityCredential(auth AzureAuthConfig) (azcore.TokenCredential, error) {
+	options := &azidentity.ManagedIdentityCredentialOptions{}
+	if auth.ManagedIdentityClientID != "" {
+		options.ID = azidentity.ClientID(auth.ManagedIdentityClientID)
+	}
+	return azidentity.NewManagedIdentityCredential(options)
+}
+
+// chainedCredential builds a ChainedTokenCredential over every credential
+// the config has enough information to construct. Each source is wrapped
+// so we can log which one actually authenticated.
+func chainedCredential(auth AzureAuthConfig) (azcore.TokenCredential, error) {
+	var sources []namedCredential
+
+	if auth.TenantID != "" && auth.ClientID != "" && auth.FederatedTokenFile != "" {
+		if cred, err := workloadIdentityCredential(auth); err == nil {
+			sources = append(sources, namedCredential{"workload-identity", cred})
+		}
+	}
+
+	if miCred, err := managedIdentityCredential(auth); err == nil {
+		sources = append(sources, namedCredential{"managed-identity", miCred})
+	}
+
+	if auth.TenantID != "" && auth.ClientID != "" && auth.ClientSecret != "" {
+		if cred, err := namedWorkloadOrServicePrincipal(auth); err == nil {
+			sources = append(sources, namedCredential{"service-principal", cred})
+		}
+	}
+
+	if defaultCred, err := azidentity.NewDefaultAzureCredential(nil); err == nil {
+		sources = append(sources, namedCredential{"default-azure-credential", defaultCred})
+	}
+
+	if len(sources) == 0 {
+		return nil, fmt.Errorf("no usable Azure credential could be constructed from configuration")
+	}
+
+	wrapped := make([]azcore.TokenCredential, 0, len(sources))
+	for _, s := range sources {
+		wrapped = append(wrapped, &loggingCredential{name: s.name, cred: s.cred})
+	}
+
+	return azidentity.NewChainedTokenCredential(wrapped, nil)
+}
@@ -3,10 +3,10 @@ package azure
 import (
 	"fmt"
 
-	"github.com/CAST-Intelligence/elysium-usv/internal/config"
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
 	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob"
 	"github.com/Azure/azure-sdk-for-go/sdk/storage/azqueue"
+	"github.com/CAST-Intelligence/elysium-usv/internal/config"
 )
 
 // Client encapsulates all Azure services used by the application
@@ -15,9 +15,16 @@ type Client struct {
 	QueueClient *azqueue.ServiceClient
 }
 
-// NewClient creates a new Azure client with the given configuration
+// NewClient creates a new Azure client with the given configuration.
+//
+// Authentication is selected via cfg.AzureAuthMode: "connection-string",
+// "account-key", "sas", "service-principal", "workload-identity",
+// "managed-identity", "key-vault" (fetches the connection string itself
+// from Key Vault), or "". With "", cfg.AzureStorageConnectionString is used
+// if it is set; otherwise a ChainedTokenCredential is built that tries
+// workload identity, managed identity, and service principal before
+// falling back to the Azure CLI/environment defaults. See auth.go.
 func NewClient(cfg *config.Config) (*Client, error) {
-	// Create the Azure clients
 	blobClient, err := createBlobClient(cfg)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create blob client: %w", err)
@@ -34,34 +41,116 @@ func NewClient(cfg *config.Config) (*Client, error) {
 	}, nil
 }
 
-// createBlobClient creates a new Azure Blob Storage client
+// createBlobClient creates a new Azure Blob Storage client using the
+// auth mode selected in cfg.
 func createBlobClient(cfg *config.Config) (*azblob.Client, error) {
-	// Try to use connection string first
-	if cfg.AzureStorageConnectionString != "" {
-		return azblob.NewClientFromConnectionString(cfg.AzureStorageConnectionString, nil)
-	}
+	auth := newAuthConfig(cfg)
+	clientOptions := auth.ClientOptions
 
-	// Fall back to managed identity or other authentication methods
-	cred, err := azidentity.NewDefaultAzureCredential(nil)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create credential: %w", err)
-	}
+	switch auth.Mode {
+	case "account-key":
+		if auth.AccountName == "" || auth.AccountKey == "" {
+			return nil, fmt.Errorf("AZURE_ACCOUNT_NAME and AZURE_ACCOUNT_KEY are required for account-key auth")
+		}
+		cred, err := azblob.NewSharedKeyCredential(auth.AccountName, auth.AccountKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create shared key credential: %w", err)
+		}
+		return azblob.NewClientWithSharedKeyCredential(blobEndpoint(auth.AccountName), cred, toBlobClientOptions(clientOptions))
+
+	case "sas":
+		if auth.SASURL == "" {
+			return nil, fmt.Errorf("AZURE_SAS_URL is required for sas auth")
+		}
+		return azblob.NewClientWithNoCredential(auth.SASURL, toBlobClientOptions(clientOptions))
 
-	return azblob.NewClient("https://ACCOUNT_NAME.blob.core.windows.net", cred, nil)
+	case "connection-string", "key-vault":
+		connStr, err := resolveConnectionString(cfg)
+		if err != nil {
+			return nil, err
+		}
+		return azblob.NewClientFromConnectionString(connStr, toBlobClientOptions(clientOptions))
+
+	default:
+		if auth.Mode == "" && cfg.AzureStorageConnectionString != "" {
+			return azblob.NewClientFromConnectionString(cfg.AzureStorageConnectionString, toBlobClientOptions(clientOptions))
+		}
+
+		cred, err := buildCredential(auth)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create credential: %w", err)
+		}
+		if auth.AccountName == "" {
+			return nil, fmt.Errorf("AZURE_ACCOUNT_NAME is required when authenticating with an Azure AD token credential")
+		}
+		return azblob.NewClient(blobEndpoint(auth.AccountName), cred, toBlobClientOptions(clientOptions))
+	}
 }
 
-// createQueueClient creates a new Azure Queue Storage client
+// createQueueClient creates a new Azure Queue Storage client using the
+// auth mode selected in cfg.
 func createQueueClient(cfg *config.Config) (*azqueue.ServiceClient, error) {
-	// Try to use connection string first
-	if cfg.AzureStorageConnectionString != "" {
-		return azqueue.NewServiceClientFromConnectionString(cfg.AzureStorageConnectionString, nil)
+	auth := newAuthConfig(cfg)
+	clientOptions := auth.ClientOptions
+
+	switch auth.Mode {
+	case "account-key":
+		if auth.AccountName == "" || auth.AccountKey == "" {
+			return nil, fmt.Errorf("AZURE_ACCOUNT_NAME and AZURE_ACCOUNT_KEY are required for account-key auth")
+		}
+		cred, err := azqueue.NewSharedKeyCredential(auth.AccountName, auth.AccountKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create shared key credential: %w", err)
+		}
+		return azqueue.NewServiceClientWithSharedKeyCredential(queueEndpoint(auth.AccountName), cred, toQueueClientOptions(clientOptions))
+
+	case "sas":
+		if auth.SASURL == "" {
+			return nil, fmt.Errorf("AZURE_SAS_URL is required for sas auth")
+		}
+		return azqueue.NewServiceClientWithNoCredential(auth.SASURL, toQueueClientOptions(clientOptions))
+
+	case "connection-string", "key-vault":
+		connStr, err := resolveConnectionString(cfg)
+		if err != nil {
+			return nil, err
+		}
+		return azqueue.NewServiceClientFromConnectionString(connStr, toQueueClientOptions(clientOptions))
+
+	default:
+		if auth.Mode == "" && cfg.AzureStorageConnectionString != "" {
+			return azqueue.NewServiceClientFromConnectionString(cfg.AzureStorageConnectionString, toQueueClientOptions(clientOptions))
+		}
+
+		cred, err := buildCredential(auth)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create credential: %w", err)
+		}
+		if auth.AccountName == "" {
+			return nil, fmt.Errorf("AZURE_ACCOUNT_NAME is required when authenticating with an Azure AD token credential")
+		}
+		return azqueue.NewServiceClient(queueEndpoint(auth.AccountName), cred, toQueueClientOptions(clientOptions))
 	}
+}
 
-	// Fall back to managed identity or other authentication methods
-	cred, err := azidentity.NewDefaultAzureCredential(nil)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create credential: %w", err)
+func blobEndpoint(accountName string) string {
+	return fmt.Sprintf("https://%s.blob.core.windows.net", accountName)
+}
+
+func queueEndpoint(accountName string) string {
+	return fmt.Sprintf("https://%s.queue.core.windows.net", accountName)
+}
+
+func toBlobClientOptions(opts *policy.ClientOptions) *azblob.ClientOptions {
+	if opts == nil {
+		return nil
 	}
+	return &azblob.ClientOptions{ClientOptions: *opts}
+}
 
-	return azqueue.NewServiceClient("https://ACCOUNT_NAME.queue.core.windows.net", cred, nil)
-}
\ No newline at end of file
+func toQueueClientOptions(opts *policy.ClientOptions) *azqueue.ClientOptions {
+	if opts == nil {
+		return nil
+	}
+	return &azqueue.ClientOptions{ClientOptions: *opts}
+}
@@ -1,6 +1,7 @@
 package config
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
 	"strconv"
@@ -15,6 +16,29 @@ type Config struct {
 	AzureKeyVaultName           string
 	AzureKeyVaultEndpoint       string
 
+	// AzureAuthMode selects how the Azure Storage clients authenticate:
+	// "connection-string", "account-key", "sas", "service-principal",
+	// "workload-identity", "managed-identity", "key-vault", or "" to try a
+	// chain of credentials in priority order.
+	AzureAuthMode                   string
+	AzureAccountName                string
+	AzureAccountKey                 string
+	AzureSASURL                     string
+	AzureTenantID                   string
+	AzureClientID                   string
+	AzureClientSecret               string
+	AzureFederatedTokenFile         string
+	AzureManagedIdentityClientID    string
+	AzureConnectionStringSecretName string
+
+	// AzureClientRetryMaxAttempts and AzureClientRetryTimeout tune the retry
+	// policy applied to every request made by the Azure blob and queue
+	// clients; the audit table client keeps the SDK's default policy. Leave
+	// AzureClientRetryMaxAttempts at 0 to use the SDK's
+	// own default instead of overriding it.
+	AzureClientRetryMaxAttempts int
+	AzureClientRetryTimeout     time.Duration
+
 	// AWS settings
 	AWSEndpointURL  string
 	AWSAccessKey    string
@@ -22,12 +46,42 @@ type Config struct {
 	AWSRegion       string
 	AWSBucketName   string
 
+	// S3 multipart transfer settings. S3UploadPartSize and
+	// S3UploadConcurrency are used by aws.S3Client to stream uploads in
+	// bounded chunks instead of buffering whole objects in memory.
+	// S3DownloadPartSize and S3DownloadConcurrency are loaded but no
+	// download path reads them.
+	S3UploadPartSize      int64
+	S3UploadConcurrency   int
+	S3DownloadPartSize    int64
+	S3DownloadConcurrency int
+
+	// AWSAuthMode selects how aws.S3Client authenticates: "static" (the
+	// AWSAccessKey/AWSSecretKey pair above), "iam" (EC2/ECS instance
+	// profile credentials via IMDS), or "web-identity" (IRSA-style OIDC
+	// federation on EKS). Leave empty for "static".
+	AWSAuthMode             string
+	AWSRoleARN              string
+	AWSWebIdentityTokenFile string
+	AWSRoleSessionName      string
+
+	// Clock-skew guard settings, used by aws.S3Client to detect a drifting
+	// local clock (common on ships without reliable NTP) before it causes
+	// signed requests to fail opaquely.
+	MaxClockSkew     time.Duration
+	AllowSkewedClock bool
+
 	// Server settings
 	Port            int
 	ReadTimeout     time.Duration
 	WriteTimeout    time.Duration
 	ShutdownTimeout time.Duration
 
+	// MetricsListenAddr, if set, serves /metrics on its own HTTP listener
+	// (e.g. ":9090") instead of sharing the main API port, so a firewall
+	// can expose scraping without exposing the rest of the API.
+	MetricsListenAddr string
+
 	// Pipeline settings
 	WorkerCount            int
 	ValidationQueueName    string
@@ -39,6 +93,30 @@ type Config struct {
 	OperationRetryCount    int
 	OperationRetryInterval time.Duration
 
+	// VisibilityTimeout is how long a dequeued message is hidden from other
+	// workers before it becomes visible again. VisibilityRefreshInterval is
+	// how often ValidationWorker's lease refresher renews that timeout for
+	// a message still being processed, so a long-running validation
+	// doesn't let the message reappear and get processed twice.
+	VisibilityTimeout         time.Duration
+	VisibilityRefreshInterval time.Duration
+
+	// StorageDriver selects the storage.ObjectStore driver ValidationWorker
+	// reads and stamps blobs through: "azure" (the default, backed by
+	// BlobContainerName) or "filesystem" (a local directory, for running
+	// the pipeline end-to-end in tests without Azurite). LocalStoreRootDir
+	// is only used by the filesystem driver.
+	StorageDriver     string
+	LocalStoreRootDir string
+
+	// AdminAPIToken gates the server package's /api/v1/admin/workers
+	// endpoints (pause/resume/reconfigure workers at runtime): requests must
+	// present it via the X-Admin-Token header. Leave unset to disable the
+	// admin API entirely (it responds 503), since these endpoints mutate
+	// live worker behavior and shouldn't be reachable without an operator
+	// opting in.
+	AdminAPIToken string
+
 	// FTP settings
 	FTPWatchEnabled bool
 	FTPWatchDir     string
@@ -50,9 +128,79 @@ type Config struct {
 	FTPRetryCount   int
 	FTPRetryDelay   time.Duration
 
+	// Secure transfer settings (SFTP/FTPS), used by SecureTransferWorker
+	SecureTransferEnabled bool
+	TransferProtocol      string // "sftp" or "ftps"
+	TransferWatchDir      string
+	TransferPollInterval  time.Duration
+	TransferHost          string
+	TransferPort          int
+	TransferUser          string
+	TransferPassword      string
+	SFTPPrivateKeyPath    string
+	SFTPKnownHostsPath    string
+	FTPSClientCertPath    string
+	FTPSClientKeyPath     string
+	FTPSCACertPath        string
+	FSNotifyEnabled       bool
+
+	// Streaming upload settings, used by FTPWorker and SecureTransferWorker
+	UploadBlockSize      int64
+	UploadConcurrency    int
+	MaxMemoryPerTransfer int64
+
 	// Logging settings
 	LogLevel string
 	LogJSON  bool
+
+	// Audit settings
+	AuditSinkType              string
+	AuditDir                   string
+	AuditTableName             string
+	AuditSigningKeyPath        string
+	AuditSigningKeyVaultSecret string
+
+	// Dead-letter settings, used by the validation, transfer, and cleanup
+	// workers to move poison messages aside after repeated failures
+	DeadLetterQueueName   string
+	MaxDeliveryCount      int32
+	DeadLetterBaseBackoff time.Duration
+	DeadLetterMaxBackoff  time.Duration
+
+	// Blob trash settings, used by CleanupWorker to hold transferred blobs
+	// in a recoverable trashed state for BlobTrashLifetime before the
+	// second "empty trash" scan physically deletes them
+	BlobTrashLifetime      time.Duration
+	BlobTrashCheckInterval time.Duration
+	EmptyTrashBatchSize    int
+
+	// Resumable-upload reconciliation settings, used by TransferWorker's
+	// reconciliation sweep to find S3 multipart uploads left dangling by a
+	// crash or restart: one older than UploadStaleTTL is aborted outright
+	// rather than left for a resume attempt that may never come.
+	UploadReconcileInterval time.Duration
+	UploadStaleTTL          time.Duration
+
+	// RaceWindow is how recently a blob must have been modified for
+	// TransferValidatedBlob to skip it and requeue rather than risk
+	// transferring it: a blob written (or rewritten by a concurrent
+	// transfer attempt) inside this window might still be mid-write.
+	RaceWindow time.Duration
+
+	// Destinations lists the storage.Volume backends a validated blob is
+	// transferred to, in order. Parsed from DESTINATIONS_JSON; if unset,
+	// Load falls back to a single "s3" destination built from the legacy
+	// AWS_* settings above so existing single-bucket deployments keep
+	// working unchanged.
+	Destinations []DestinationSpec
+}
+
+// DestinationSpec names one storage.Volume driver and the params its
+// factory needs to construct it (e.g. "bucket", "region" for the s3
+// driver, or "root_dir" for the file driver).
+type DestinationSpec struct {
+	Driver string            `json:"driver"`
+	Params map[string]string `json:"params"`
 }
 
 // Default configuration values
@@ -70,11 +218,39 @@ const (
 	defaultBatchSize           = 10
 	defaultRetryCount          = 3
 	defaultRetryInterval       = 5 * time.Second
+	defaultVisibilityTimeout         = 30 * time.Second
+	defaultVisibilityRefreshInterval = 15 * time.Second
 	defaultFTPPollInterval     = 30 * time.Second
 	defaultFTPPort             = 21
 	defaultFTPRetryCount       = 3
 	defaultFTPRetryDelay       = 5 * time.Second
 	defaultLogLevel            = "info"
+	defaultAuditSinkType       = "filesystem"
+	defaultTransferProtocol    = "sftp"
+	defaultTransferPort        = 22
+	defaultTransferPollInterval = 30 * time.Second
+	defaultUploadBlockSize       = 4 * 1024 * 1024 // 4 MiB
+	defaultUploadConcurrency    = 4
+	defaultMaxMemoryPerTransfer = 64 * 1024 * 1024 // 64 MiB
+	defaultDeadLetterQueueName   = "dead-letter-queue"
+	defaultMaxDeliveryCount      = 5
+	defaultDeadLetterBaseBackoff = 10 * time.Second
+	defaultDeadLetterMaxBackoff  = 10 * time.Minute
+	defaultS3UploadPartSize      = 5 * 1024 * 1024 // 5 MiB, the s3manager default
+	defaultS3UploadConcurrency   = 5
+	defaultS3DownloadPartSize    = 5 * 1024 * 1024 // 5 MiB
+	defaultS3DownloadConcurrency = 13
+	defaultAWSRoleSessionName    = "usvpipeline"
+	defaultMaxClockSkew          = 600 * time.Second // matches common S3 signer tolerance
+	defaultAzureClientRetryTimeout = 60 * time.Second
+	defaultBlobTrashLifetime      = 72 * time.Hour
+	defaultBlobTrashCheckInterval = 1 * time.Hour
+	defaultEmptyTrashBatchSize    = 10
+	defaultUploadReconcileInterval = 15 * time.Minute
+	defaultUploadStaleTTL          = 24 * time.Hour
+	defaultRaceWindow              = 2 * time.Minute
+	defaultStorageDriver          = "azure"
+	defaultLocalStoreRootDir      = "./data/objectstore"
 )
 
 // Load loads configuration from environment variables
@@ -85,6 +261,19 @@ func Load() (*Config, error) {
 		AzureKeyVaultName:           os.Getenv("AZURE_KEY_VAULT_NAME"),
 		AzureKeyVaultEndpoint:       os.Getenv("AZURE_KEY_VAULT_ENDPOINT"),
 
+		AzureAuthMode:                   getEnvOrDefault("AZURE_AUTH_MODE", ""),
+		AzureAccountName:                getEnvOrDefault("AZURE_ACCOUNT_NAME", ""),
+		AzureAccountKey:                 getEnvOrDefault("AZURE_ACCOUNT_KEY", ""),
+		AzureSASURL:                     getEnvOrDefault("AZURE_SAS_URL", ""),
+		AzureTenantID:                   getEnvOrDefault("AZURE_TENANT_ID", ""),
+		AzureClientID:                   getEnvOrDefault("AZURE_CLIENT_ID", ""),
+		AzureClientSecret:               getEnvOrDefault("AZURE_CLIENT_SECRET", ""),
+		AzureFederatedTokenFile:         getEnvOrDefault("AZURE_FEDERATED_TOKEN_FILE", ""),
+		AzureManagedIdentityClientID:    getEnvOrDefault("AZURE_MANAGED_IDENTITY_CLIENT_ID", ""),
+		AzureConnectionStringSecretName: getEnvOrDefault("AZURE_CONNECTION_STRING_SECRET_NAME", ""),
+		AzureClientRetryMaxAttempts:     getEnvAsIntOrDefault("AZURE_CLIENT_RETRY_MAX_ATTEMPTS", 0),
+		AzureClientRetryTimeout:         getEnvAsDurationOrDefault("AZURE_CLIENT_RETRY_TIMEOUT", defaultAzureClientRetryTimeout),
+
 		// AWS settings
 		AWSEndpointURL: os.Getenv("AWS_ENDPOINT_URL"),
 		AWSAccessKey:   os.Getenv("AWS_ACCESS_KEY_ID"),
@@ -92,12 +281,30 @@ func Load() (*Config, error) {
 		AWSRegion:      os.Getenv("AWS_REGION"),
 		AWSBucketName:  getEnvOrDefault("AWS_BUCKET_NAME", "revelare-vessel-data"),
 
+		S3UploadPartSize:      getEnvAsInt64OrDefault("S3_UPLOAD_PART_SIZE", defaultS3UploadPartSize),
+		S3UploadConcurrency:   getEnvAsIntOrDefault("S3_UPLOAD_CONCURRENCY", defaultS3UploadConcurrency),
+		S3DownloadPartSize:    getEnvAsInt64OrDefault("S3_DOWNLOAD_PART_SIZE", defaultS3DownloadPartSize),
+		S3DownloadConcurrency: getEnvAsIntOrDefault("S3_DOWNLOAD_CONCURRENCY", defaultS3DownloadConcurrency),
+
+		AWSAuthMode: getEnvOrDefault("AWS_AUTH_MODE", ""),
+		// AWS_ROLE_ARN and AWS_WEB_IDENTITY_TOKEN_FILE match the env vars
+		// EKS's IRSA pod webhook injects automatically, so web-identity
+		// auth works without any USV-specific configuration in that case.
+		AWSRoleARN:              getEnvOrDefault("AWS_ROLE_ARN", ""),
+		AWSWebIdentityTokenFile: getEnvOrDefault("AWS_WEB_IDENTITY_TOKEN_FILE", ""),
+		AWSRoleSessionName:      getEnvOrDefault("AWS_ROLE_SESSION_NAME", defaultAWSRoleSessionName),
+
+		MaxClockSkew:     getEnvAsDurationOrDefault("MAX_CLOCK_SKEW", defaultMaxClockSkew),
+		AllowSkewedClock: getEnvAsBoolOrDefault("ALLOW_SKEWED_CLOCK", false),
+
 		// Server settings
 		Port:            getEnvAsIntOrDefault("PORT", defaultPort),
 		ReadTimeout:     getEnvAsDurationOrDefault("READ_TIMEOUT", defaultReadTimeout),
 		WriteTimeout:    getEnvAsDurationOrDefault("WRITE_TIMEOUT", defaultWriteTimeout),
 		ShutdownTimeout: getEnvAsDurationOrDefault("SHUTDOWN_TIMEOUT", defaultShutdownTimeout),
 
+		MetricsListenAddr: getEnvOrDefault("METRICS_LISTEN_ADDR", ""),
+
 		// Pipeline settings
 		WorkerCount:            getEnvAsIntOrDefault("WORKER_COUNT", defaultWorkerCount),
 		ValidationQueueName:    getEnvOrDefault("VALIDATION_QUEUE_NAME", defaultValidationQueueName),
@@ -109,6 +316,15 @@ func Load() (*Config, error) {
 		OperationRetryCount:    getEnvAsIntOrDefault("OPERATION_RETRY_COUNT", defaultRetryCount),
 		OperationRetryInterval: getEnvAsDurationOrDefault("OPERATION_RETRY_INTERVAL", defaultRetryInterval),
 
+		VisibilityTimeout:         getEnvAsDurationOrDefault("VISIBILITY_TIMEOUT", defaultVisibilityTimeout),
+		VisibilityRefreshInterval: getEnvAsDurationOrDefault("VISIBILITY_REFRESH_INTERVAL", defaultVisibilityRefreshInterval),
+
+		StorageDriver:     getEnvOrDefault("STORAGE_DRIVER", defaultStorageDriver),
+		LocalStoreRootDir: getEnvOrDefault("LOCAL_STORE_ROOT_DIR", defaultLocalStoreRootDir),
+
+		// Admin API stays disabled (AdminAPIToken == "") unless explicitly configured.
+		AdminAPIToken: getEnvOrDefault("ADMIN_API_TOKEN", ""),
+
 		// FTP settings
 		FTPWatchEnabled: getEnvAsBoolOrDefault("FTP_WATCH_ENABLED", false),
 		FTPWatchDir:     getEnvOrDefault("FTP_WATCH_DIR", ""),
@@ -120,10 +336,58 @@ func Load() (*Config, error) {
 		FTPRetryCount:   getEnvAsIntOrDefault("FTP_RETRY_COUNT", defaultFTPRetryCount),
 		FTPRetryDelay:   getEnvAsDurationOrDefault("FTP_RETRY_DELAY", defaultFTPRetryDelay),
 
+		// Secure transfer settings
+		SecureTransferEnabled: getEnvAsBoolOrDefault("SECURE_TRANSFER_ENABLED", false),
+		TransferProtocol:      getEnvOrDefault("TRANSFER_PROTOCOL", defaultTransferProtocol),
+		TransferWatchDir:      getEnvOrDefault("TRANSFER_WATCH_DIR", ""),
+		TransferPollInterval:  getEnvAsDurationOrDefault("TRANSFER_POLL_INTERVAL", defaultTransferPollInterval),
+		TransferHost:          getEnvOrDefault("TRANSFER_HOST", "localhost"),
+		TransferPort:          getEnvAsIntOrDefault("TRANSFER_PORT", defaultTransferPort),
+		TransferUser:          getEnvOrDefault("TRANSFER_USER", ""),
+		TransferPassword:      getEnvOrDefault("TRANSFER_PASSWORD", ""),
+		SFTPPrivateKeyPath:    getEnvOrDefault("SFTP_PRIVATE_KEY_PATH", ""),
+		SFTPKnownHostsPath:    getEnvOrDefault("SFTP_KNOWN_HOSTS_PATH", ""),
+		FTPSClientCertPath:    getEnvOrDefault("FTPS_CLIENT_CERT_PATH", ""),
+		FTPSClientKeyPath:     getEnvOrDefault("FTPS_CLIENT_KEY_PATH", ""),
+		FTPSCACertPath:        getEnvOrDefault("FTPS_CA_CERT_PATH", ""),
+		FSNotifyEnabled:       getEnvAsBoolOrDefault("FSNOTIFY_ENABLED", false),
+
+		// Streaming upload settings
+		UploadBlockSize:      getEnvAsInt64OrDefault("UPLOAD_BLOCK_SIZE", defaultUploadBlockSize),
+		UploadConcurrency:    getEnvAsIntOrDefault("UPLOAD_CONCURRENCY", defaultUploadConcurrency),
+		MaxMemoryPerTransfer: getEnvAsInt64OrDefault("MAX_MEMORY_PER_TRANSFER", defaultMaxMemoryPerTransfer),
+
 		// Logging settings
 		LogLevel: getEnvOrDefault("LOG_LEVEL", defaultLogLevel),
 		LogJSON:  getEnvAsBoolOrDefault("LOG_JSON", false),
+
+		// Audit settings
+		AuditSinkType:              getEnvOrDefault("AUDIT_SINK_TYPE", defaultAuditSinkType),
+		AuditDir:                   getEnvOrDefault("AUDIT_DIR", ""),
+		AuditTableName:             getEnvOrDefault("AUDIT_TABLE_NAME", ""),
+		AuditSigningKeyPath:        getEnvOrDefault("AUDIT_SIGNING_KEY_PATH", ""),
+		AuditSigningKeyVaultSecret: getEnvOrDefault("AUDIT_SIGNING_KEY_VAULT_SECRET", ""),
+
+		// Dead-letter settings
+		DeadLetterQueueName:   getEnvOrDefault("DEAD_LETTER_QUEUE_NAME", defaultDeadLetterQueueName),
+		MaxDeliveryCount:      int32(getEnvAsIntOrDefault("MAX_DELIVERY_COUNT", defaultMaxDeliveryCount)),
+		DeadLetterBaseBackoff: getEnvAsDurationOrDefault("DEAD_LETTER_BASE_BACKOFF", defaultDeadLetterBaseBackoff),
+		DeadLetterMaxBackoff:  getEnvAsDurationOrDefault("DEAD_LETTER_MAX_BACKOFF", defaultDeadLetterMaxBackoff),
+
+		BlobTrashLifetime:      getEnvAsDurationOrDefault("BLOB_TRASH_LIFETIME", defaultBlobTrashLifetime),
+		BlobTrashCheckInterval: getEnvAsDurationOrDefault("BLOB_TRASH_CHECK_INTERVAL", defaultBlobTrashCheckInterval),
+		EmptyTrashBatchSize:    getEnvAsIntOrDefault("EMPTY_TRASH_BATCH_SIZE", defaultEmptyTrashBatchSize),
+
+		UploadReconcileInterval: getEnvAsDurationOrDefault("UPLOAD_RECONCILE_INTERVAL", defaultUploadReconcileInterval),
+		UploadStaleTTL:          getEnvAsDurationOrDefault("UPLOAD_STALE_TTL", defaultUploadStaleTTL),
+		RaceWindow:              getEnvAsDurationOrDefault("RACE_WINDOW", defaultRaceWindow),
+	}
+
+	destinations, err := loadDestinations(os.Getenv("DESTINATIONS_JSON"), &config)
+	if err != nil {
+		return nil, err
 	}
+	config.Destinations = destinations
 
 	// Validate required settings
 	if err := config.validateRequired(); err != nil {
@@ -133,15 +397,96 @@ func Load() (*Config, error) {
 	return &config, nil
 }
 
+// loadDestinations parses DESTINATIONS_JSON into a []DestinationSpec. If the
+// env var is unset, it falls back to a single "s3" destination built from
+// the legacy AWS_* fields already loaded onto cfg, so existing
+// single-bucket deployments don't need to change anything.
+func loadDestinations(destinationsJSON string, cfg *Config) ([]DestinationSpec, error) {
+	if destinationsJSON == "" {
+		return []DestinationSpec{
+			{
+				Driver: "s3",
+				Params: map[string]string{
+					"endpoint_url":            cfg.AWSEndpointURL,
+					"access_key_id":           cfg.AWSAccessKey,
+					"secret_access_key":       cfg.AWSSecretKey,
+					"region":                  cfg.AWSRegion,
+					"bucket":                  cfg.AWSBucketName,
+					"auth_mode":               cfg.AWSAuthMode,
+					"role_arn":                cfg.AWSRoleARN,
+					"web_identity_token_file": cfg.AWSWebIdentityTokenFile,
+					"role_session_name":       cfg.AWSRoleSessionName,
+					"part_size":               strconv.FormatInt(cfg.S3UploadPartSize, 10),
+					"upload_concurrency":      strconv.Itoa(cfg.S3UploadConcurrency),
+					"max_clock_skew":          cfg.MaxClockSkew.String(),
+					"allow_skewed_clock":      strconv.FormatBool(cfg.AllowSkewedClock),
+				},
+			},
+		}, nil
+	}
+
+	var destinations []DestinationSpec
+	if err := json.Unmarshal([]byte(destinationsJSON), &destinations); err != nil {
+		return nil, fmt.Errorf("failed to parse DESTINATIONS_JSON: %w", err)
+	}
+	return destinations, nil
+}
+
 // validateRequired ensures that all required configuration values are set
 func (c *Config) validateRequired() error {
-	if c.AzureStorageConnectionString == "" {
-		return fmt.Errorf("AZURE_STORAGE_CONNECTION_STRING is required")
+	switch c.AzureAuthMode {
+	case "":
+		if c.AzureStorageConnectionString == "" && c.AzureAccountName == "" {
+			return fmt.Errorf("AZURE_STORAGE_CONNECTION_STRING or AZURE_ACCOUNT_NAME is required when AZURE_AUTH_MODE is empty")
+		}
+	case "connection-string":
+		if c.AzureStorageConnectionString == "" {
+			return fmt.Errorf("AZURE_STORAGE_CONNECTION_STRING is required when AZURE_AUTH_MODE is %q", c.AzureAuthMode)
+		}
+	case "account-key":
+		if c.AzureAccountName == "" || c.AzureAccountKey == "" {
+			return fmt.Errorf("AZURE_ACCOUNT_NAME and AZURE_ACCOUNT_KEY are required when AZURE_AUTH_MODE is \"account-key\"")
+		}
+	case "sas":
+		if c.AzureSASURL == "" {
+			return fmt.Errorf("AZURE_SAS_URL is required when AZURE_AUTH_MODE is \"sas\"")
+		}
+	case "service-principal":
+		if c.AzureAccountName == "" || c.AzureTenantID == "" || c.AzureClientID == "" || c.AzureClientSecret == "" {
+			return fmt.Errorf("AZURE_ACCOUNT_NAME, AZURE_TENANT_ID, AZURE_CLIENT_ID, and AZURE_CLIENT_SECRET are required when AZURE_AUTH_MODE is \"service-principal\"")
+		}
+	case "workload-identity":
+		if c.AzureAccountName == "" || c.AzureTenantID == "" || c.AzureClientID == "" || c.AzureFederatedTokenFile == "" {
+			return fmt.Errorf("AZURE_ACCOUNT_NAME, AZURE_TENANT_ID, AZURE_CLIENT_ID, and AZURE_FEDERATED_TOKEN_FILE are required when AZURE_AUTH_MODE is \"workload-identity\"")
+		}
+	case "managed-identity":
+		if c.AzureAccountName == "" {
+			return fmt.Errorf("AZURE_ACCOUNT_NAME is required when AZURE_AUTH_MODE is \"managed-identity\"")
+		}
+	case "key-vault":
+		if c.AzureKeyVaultEndpoint == "" || c.AzureConnectionStringSecretName == "" {
+			return fmt.Errorf("AZURE_KEY_VAULT_ENDPOINT and AZURE_CONNECTION_STRING_SECRET_NAME are required when AZURE_AUTH_MODE is \"key-vault\"")
+		}
+	default:
+		return fmt.Errorf("unsupported AZURE_AUTH_MODE: %q", c.AzureAuthMode)
 	}
 
-	// In production, we need AWS credentials
-	if isProduction() && (c.AWSAccessKey == "" || c.AWSSecretKey == "" || c.AWSRegion == "") {
-		return fmt.Errorf("AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY, and AWS_REGION are required in production")
+	switch strings.ToLower(c.AWSAuthMode) {
+	case "", "static":
+		// In production, we need static AWS credentials
+		if isProduction() && (c.AWSAccessKey == "" || c.AWSSecretKey == "" || c.AWSRegion == "") {
+			return fmt.Errorf("AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY, and AWS_REGION are required in production")
+		}
+	case "iam":
+		if c.AWSRegion == "" {
+			return fmt.Errorf("AWS_REGION is required when AWS_AUTH_MODE is \"iam\"")
+		}
+	case "web-identity":
+		if c.AWSRegion == "" || c.AWSRoleARN == "" || c.AWSWebIdentityTokenFile == "" {
+			return fmt.Errorf("AWS_REGION, AWS_ROLE_ARN, and AWS_WEB_IDENTITY_TOKEN_FILE are required when AWS_AUTH_MODE is \"web-identity\"")
+		}
+	default:
+		return fmt.Errorf("unsupported AWS_AUTH_MODE: %q", c.AWSAuthMode)
 	}
 
 	// If FTP watching is enabled, we need a directory to watch
@@ -154,6 +499,27 @@ func (c *Config) validateRequired() error {
 		return fmt.Errorf("FTP_HOST, FTP_USER, and FTP_PASSWORD are required when FTP_WATCH_ENABLED is true")
 	}
 
+	// If secure transfer watching is enabled, we need connection details
+	// appropriate to the selected protocol
+	if c.SecureTransferEnabled {
+		if c.TransferHost == "" || c.TransferUser == "" {
+			return fmt.Errorf("TRANSFER_HOST and TRANSFER_USER are required when SECURE_TRANSFER_ENABLED is true")
+		}
+
+		switch strings.ToLower(c.TransferProtocol) {
+		case "sftp":
+			if c.SFTPPrivateKeyPath == "" && c.TransferPassword == "" {
+				return fmt.Errorf("SFTP_PRIVATE_KEY_PATH or TRANSFER_PASSWORD is required for the sftp transfer protocol")
+			}
+		case "ftps":
+			if c.TransferPassword == "" {
+				return fmt.Errorf("TRANSFER_PASSWORD is required for the ftps transfer protocol")
+			}
+		default:
+			return fmt.Errorf("unsupported TRANSFER_PROTOCOL: %q", c.TransferProtocol)
+		}
+	}
+
 	return nil
 }
 
@@ -183,6 +549,15 @@ func getEnvAsBoolOrDefault(key string, defaultValue bool) bool {
 	return defaultValue
 }
 
+func getEnvAsInt64OrDefault(key string, defaultValue int64) int64 {
+	if value := os.Getenv(key); value != "" {
+		if intValue, err := strconv.ParseInt(value, 10, 64); err == nil {
+			return intValue
+		}
+	}
+	return defaultValue
+}
+
 func getEnvAsDurationOrDefault(key string, defaultValue time.Duration) time.Duration {
 	if value := os.Getenv(key); value != "" {
 		if duration, err := time.ParseDuration(value); err == nil {
@@ -0,0 +1,264 @@
+// Package metrics defines the Prometheus collectors the pipeline
+// instruments its workers and cloud clients with, and the /metrics HTTP
+// handler that exposes them. Every collector lives on a Registry rather
+// than prometheus's global DefaultRegisterer, so tests (and any future
+// multi-tenant deployment running more than one pipeline in a process)
+// can each hold their own independent set of counters.
+package metrics
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Registry holds every metric the pipeline records. A nil *Registry is
+// valid: every recording method on it is a no-op, and Handler falls back to
+// prometheus's default handler, so callers that aren't wired up
+// with one (most existing tests, for instance) don't need a special case.
+type Registry struct {
+	reg *prometheus.Registry
+
+	BlobOpsTotal           *prometheus.CounterVec
+	BlobBytesTotal         *prometheus.CounterVec
+	BlobOpDurationSeconds  *prometheus.HistogramVec
+	QueueMessagesDequeued  *prometheus.CounterVec
+	QueueMessageAgeSeconds *prometheus.HistogramVec
+	WorkerLastRunTimestamp *prometheus.GaugeVec
+	S3APICallsTotal        *prometheus.CounterVec
+
+	ProcessedTotal              *prometheus.CounterVec
+	ErrorsTotal                 *prometheus.CounterVec
+	RetriesTotal                *prometheus.CounterVec
+	ProcessDurationSeconds      *prometheus.HistogramVec
+	WorkerUp                    *prometheus.GaugeVec
+	DLQTotal                    *prometheus.CounterVec
+	QueueDepth                  *prometheus.GaugeVec
+	BlobValidateDurationSeconds *prometheus.HistogramVec
+}
+
+// NewRegistry builds a Registry with every collector registered against a
+// fresh prometheus.Registry (not the global DefaultRegisterer).
+func NewRegistry() *Registry {
+	reg := prometheus.NewRegistry()
+
+	r := &Registry{
+		reg: reg,
+		BlobOpsTotal: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "blob_ops_total",
+			Help: "Count of blob storage operations by op and outcome.",
+		}, []string{"op", "status", "destination", "container"}),
+		BlobBytesTotal: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "blob_bytes_total",
+			Help: "Bytes transferred by blob storage operation.",
+		}, []string{"op", "destination", "container"}),
+		BlobOpDurationSeconds: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "blob_op_duration_seconds",
+			Help:    "Latency of blob storage operations.",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"op", "destination", "container"}),
+		QueueMessagesDequeued: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "queue_messages_dequeued_total",
+			Help: "Count of messages dequeued per queue.",
+		}, []string{"queue", "container"}),
+		QueueMessageAgeSeconds: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "queue_message_age_seconds",
+			Help:    "Age of a message (time since insertion) at the point it's dequeued.",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"queue", "container"}),
+		WorkerLastRunTimestamp: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "worker_last_run_timestamp",
+			Help: "Unix timestamp of each worker's last processing run.",
+		}, []string{"worker"}),
+		S3APICallsTotal: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "s3_api_calls_total",
+			Help: "Count of individual AWS S3 API calls by operation and outcome.",
+		}, []string{"op", "status", "destination"}),
+		ProcessedTotal: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "processed_total",
+			Help: "Count of worker processing runs that completed without error.",
+		}, []string{"worker"}),
+		ErrorsTotal: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "errors_total",
+			Help: "Count of worker processing runs that gave up after exhausting retries.",
+		}, []string{"worker"}),
+		RetriesTotal: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "retries_total",
+			Help: "Count of retry attempts a worker made after a failed processing run.",
+		}, []string{"worker"}),
+		ProcessDurationSeconds: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "process_duration_seconds",
+			Help:    "Latency of a worker's processFn, per attempt.",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"worker"}),
+		WorkerUp: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "worker_up",
+			Help: "1 if a worker is currently running, 0 if stopped.",
+		}, []string{"worker"}),
+		DLQTotal: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "dlq_total",
+			Help: "Count of messages moved to the dead-letter queue.",
+		}, []string{"queue"}),
+		QueueDepth: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "queue_depth",
+			Help: "Approximate number of messages currently on a queue, as last reported by GetProperties.",
+		}, []string{"queue"}),
+		BlobValidateDurationSeconds: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "blob_validate_duration_seconds",
+			Help:    "Latency of validating a single blob.",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"container"}),
+	}
+
+	reg.MustRegister(
+		r.BlobOpsTotal,
+		r.BlobBytesTotal,
+		r.BlobOpDurationSeconds,
+		r.QueueMessagesDequeued,
+		r.QueueMessageAgeSeconds,
+		r.WorkerLastRunTimestamp,
+		r.S3APICallsTotal,
+		r.ProcessedTotal,
+		r.ErrorsTotal,
+		r.RetriesTotal,
+		r.ProcessDurationSeconds,
+		r.WorkerUp,
+		r.DLQTotal,
+		r.QueueDepth,
+		r.BlobValidateDurationSeconds,
+	)
+
+	return r
+}
+
+// Handler returns the http.Handler that serves this registry's metrics in
+// the Prometheus text exposition format.
+func (r *Registry) Handler() http.Handler {
+	if r == nil {
+		return promhttp.Handler()
+	}
+	return promhttp.HandlerFor(r.reg, promhttp.HandlerOpts{})
+}
+
+// statusLabel converts an error into the "success"/"error" value blob_ops_total
+// and s3_api_calls_total use for their status label.
+func statusLabel(err error) string {
+	if err != nil {
+		return "error"
+	}
+	return "success"
+}
+
+// ObserveBlobOp records the outcome, size, and latency of a single blob
+// storage operation (e.g. "put", "get", "delete", "head", "list").
+func (r *Registry) ObserveBlobOp(op, destination, container string, bytes int64, duration time.Duration, err error) {
+	if r == nil {
+		return
+	}
+	r.BlobOpsTotal.WithLabelValues(op, statusLabel(err), destination, container).Inc()
+	if bytes > 0 {
+		r.BlobBytesTotal.WithLabelValues(op, destination, container).Add(float64(bytes))
+	}
+	r.BlobOpDurationSeconds.WithLabelValues(op, destination, container).Observe(duration.Seconds())
+}
+
+// ObserveQueueDequeue records that n messages were dequeued from queue.
+func (r *Registry) ObserveQueueDequeue(queue, container string, n int) {
+	if r == nil || n == 0 {
+		return
+	}
+	r.QueueMessagesDequeued.WithLabelValues(queue, container).Add(float64(n))
+}
+
+// ObserveQueueMessageAge records how old a message was when it was dequeued.
+func (r *Registry) ObserveQueueMessageAge(queue, container string, age time.Duration) {
+	if r == nil {
+		return
+	}
+	r.QueueMessageAgeSeconds.WithLabelValues(queue, container).Observe(age.Seconds())
+}
+
+// SetWorkerLastRun records the time of a worker's most recent processing run.
+func (r *Registry) SetWorkerLastRun(worker string, t time.Time) {
+	if r == nil {
+		return
+	}
+	r.WorkerLastRunTimestamp.WithLabelValues(worker).Set(float64(t.Unix()))
+}
+
+// ObserveS3APICall records a single underlying AWS SDK call, distinct from
+// ObserveBlobOp's higher-level Volume operations: one blob_ops_total "put"
+// can fan out into several s3_api_calls_total entries (CreateMultipartUpload,
+// several UploadPart calls, CompleteMultipartUpload).
+func (r *Registry) ObserveS3APICall(op, destination string, err error) {
+	if r == nil {
+		return
+	}
+	r.S3APICallsTotal.WithLabelValues(op, statusLabel(err), destination).Inc()
+}
+
+// ObserveProcess records one attempt of a worker's processFn: its latency
+// always, and errors_total only once the worker has given up (final is
+// true on the attempt that exhausted retries or succeeded).
+func (r *Registry) ObserveProcess(worker string, duration time.Duration, final bool, err error) {
+	if r == nil {
+		return
+	}
+	r.ProcessDurationSeconds.WithLabelValues(worker).Observe(duration.Seconds())
+	if !final {
+		return
+	}
+	if err != nil {
+		r.ErrorsTotal.WithLabelValues(worker).Inc()
+		return
+	}
+	r.ProcessedTotal.WithLabelValues(worker).Inc()
+}
+
+// ObserveRetry records that a worker is about to retry a failed
+// processing run.
+func (r *Registry) ObserveRetry(worker string) {
+	if r == nil {
+		return
+	}
+	r.RetriesTotal.WithLabelValues(worker).Inc()
+}
+
+// SetWorkerUp records whether a worker is currently running.
+func (r *Registry) SetWorkerUp(worker string, up bool) {
+	if r == nil {
+		return
+	}
+	if up {
+		r.WorkerUp.WithLabelValues(worker).Set(1)
+		return
+	}
+	r.WorkerUp.WithLabelValues(worker).Set(0)
+}
+
+// ObserveDLQ records that a message was moved to the dead-letter queue.
+func (r *Registry) ObserveDLQ(queue string) {
+	if r == nil {
+		return
+	}
+	r.DLQTotal.WithLabelValues(queue).Inc()
+}
+
+// SetQueueDepth records a queue's approximate message count, typically
+// read from an azqueue GetProperties response.
+func (r *Registry) SetQueueDepth(queue string, depth int32) {
+	if r == nil {
+		return
+	}
+	r.QueueDepth.WithLabelValues(queue).Set(float64(depth))
+}
+
+// ObserveBlobValidate records the latency of validating a single blob.
+func (r *Registry) ObserveBlobValidate(container string, duration time.Duration) {
+	if r == nil {
+		return
+	}
+	r.BlobValidateDurationSeconds.WithLabelValues(container).Observe(duration.Seconds())
+}
@@ -1,12 +1,17 @@
 package server
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"log"
 	"net/http"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/CAST-Intelligence/elysium-usv/internal/config"
+	"github.com/CAST-Intelligence/elysium-usv/internal/metrics"
 )
 
 // WorkerStatus interface for objects that can report status
@@ -15,22 +20,128 @@ type WorkerStatus interface {
 	LastRun() time.Time
 }
 
+// ManagedWorker is a WorkerStatus that can also be paused, resumed, and
+// reconfigured at runtime, backing the /api/v1/admin/workers endpoints.
+// Every concrete worker type in internal/worker implements this by
+// forwarding to its embedded *worker.Worker, so the server package never
+// needs to import internal/worker directly.
+type ManagedWorker interface {
+	WorkerStatus
+	Pause()
+	Resume()
+	IsPaused() bool
+	Interval() time.Duration
+	BatchSize() int
+	RetryCount() int
+
+	// Reconfigure applies new runtime tunables, picked up on the worker's
+	// next tick without a restart. A zero value leaves the corresponding
+	// field unchanged. visibilityTimeout is ignored by workers that have no
+	// such tunable (see VisibilityTimeoutReporter).
+	Reconfigure(interval time.Duration, batchSize, retryCount int, visibilityTimeout time.Duration)
+}
+
+// VisibilityTimeoutReporter is implemented by ManagedWorkers that lease
+// messages from a queue with a visibility timeout (currently only
+// ValidationWorker), so the admin API can report and reconfigure that
+// tunable where it applies without adding it to ManagedWorker itself.
+type VisibilityTimeoutReporter interface {
+	VisibilityTimeout() time.Duration
+}
+
+// S3AuthStatus reports an aws.S3Client's currently active credential
+// lifecycle for the status endpoints. It's a narrow interface (rather than
+// importing internal/aws directly) so the server package doesn't need to
+// depend on the AWS SDK.
+type S3AuthStatus interface {
+	AuthMode() string
+	AuthExpiration() time.Time
+}
+
+// BlobRestorer can undo a pending trash operation on a blob, used to back
+// the POST /blobs/{name}/untrash endpoint. CleanupWorker implements this.
+type BlobRestorer interface {
+	RestoreBlob(ctx context.Context, blobName string) error
+}
+
+// ClockSkewReporter reports a storage client's most recently measured clock
+// skew against its backend's clock, for the status endpoints to surface so
+// operators can spot drifting vessels. aws.S3Client implements this.
+type ClockSkewReporter interface {
+	ClockSkew() time.Duration
+}
+
+// PoisonLister lists every message currently dead-lettered across the
+// pipeline's queues, for the GET /api/v1/poison endpoint. It's a narrow
+// interface (rather than importing internal/worker's DeadLetterSink
+// directly) so the server package doesn't need to depend on the Azure
+// Storage Queue SDK; its return value just needs to be JSON-marshalable.
+type PoisonLister interface {
+	ListAll(ctx context.Context) (interface{}, error)
+}
+
+// WorkerRegistry holds every pipeline worker the admin and status endpoints
+// can address by name (e.g. "validation", "transfer", "cleanup"), replacing
+// the fixed set of package-level worker variables this package used to
+// carry so new worker types don't require server.go changes to expose.
+type WorkerRegistry struct {
+	mu      sync.RWMutex
+	workers map[string]ManagedWorker
+}
+
+func newWorkerRegistry() *WorkerRegistry {
+	return &WorkerRegistry{workers: make(map[string]ManagedWorker)}
+}
+
+// Set replaces the full set of registered workers.
+func (r *WorkerRegistry) Set(workers map[string]ManagedWorker) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.workers = workers
+}
+
+// Get looks up a worker by name.
+func (r *WorkerRegistry) Get(name string) (ManagedWorker, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	w, ok := r.workers[name]
+	return w, ok
+}
+
+// All returns a snapshot copy of the registered workers, safe to range over
+// without holding the registry's lock.
+func (r *WorkerRegistry) All() map[string]ManagedWorker {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	all := make(map[string]ManagedWorker, len(r.workers))
+	for name, w := range r.workers {
+		all[name] = w
+	}
+	return all
+}
+
 // Global status trackers
 var (
-	validationWorker WorkerStatus
-	transferWorker   WorkerStatus
-	cleanupWorker    WorkerStatus
-	ftpWorker        WorkerStatus
-	additionalWorkers []WorkerStatus
+	workers           = newWorkerRegistry()
+	blobRestorer      BlobRestorer
+	s3AuthStatus      S3AuthStatus
+	metricsRegistry   *metrics.Registry
+	clockSkewReporter ClockSkewReporter
+	poisonLister      PoisonLister
+	adminAPIToken     string
 )
 
-// New creates a new HTTP server with the given configuration
+// New creates a new HTTP server with the given configuration. If
+// cfg.MetricsListenAddr is set, /metrics is served only from the server
+// returned by NewMetricsServer, not on this one.
 func New(cfg *config.Config) *http.Server {
+	adminAPIToken = cfg.AdminAPIToken
+
 	// Create a new router
 	mux := http.NewServeMux()
 
 	// Register routes
-	registerRoutes(mux)
+	registerRoutes(mux, cfg.MetricsListenAddr == "")
 
 	// Create and return the server
 	return &http.Server{
@@ -42,48 +153,70 @@ func New(cfg *config.Config) *http.Server {
 	}
 }
 
-// RegisterWorkerStatusEndpoints registers worker status objects with the server
-func RegisterWorkerStatusEndpoints(srv *http.Server, workers ...WorkerStatus) {
-	// Clear additionalWorkers slice
-	additionalWorkers = nil
-	
-	// Ensure we have at least the three main workers
-	if len(workers) >= 3 {
-		validationWorker = workers[0]
-		transferWorker = workers[1]
-		cleanupWorker = workers[2]
-		
-		// If there's an FTP worker (4th worker), register it
-		if len(workers) >= 4 {
-			ftpWorker = workers[3]
-		}
-		
-		// Register any additional workers beyond the standard ones
-		if len(workers) > 4 {
-			additionalWorkers = workers[4:]
-		}
-	} else {
-		// Handle the case where we have fewer than 3 workers
-		for i, w := range workers {
-			switch i {
-			case 0:
-				validationWorker = w
-			case 1:
-				transferWorker = w
-			case 2:
-				cleanupWorker = w
-			}
+// NewMetricsServer creates a standalone HTTP server exposing only
+// /metrics on cfg.MetricsListenAddr, for deployments that want to firewall
+// off scraping separately from the rest of the API. Only meaningful when
+// cfg.MetricsListenAddr is non-empty; callers should check that first.
+func NewMetricsServer(cfg *config.Config) *http.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/metrics", metricsHandler)
+
+	return &http.Server{
+		Addr:         cfg.MetricsListenAddr,
+		Handler:      mux,
+		ReadTimeout:  cfg.ReadTimeout,
+		WriteTimeout: cfg.WriteTimeout,
+		IdleTimeout:  120 * time.Second,
+	}
+}
+
+// RegisterWorkerStatusEndpoints registers the pipeline's workers, keyed by
+// name (e.g. "validation", "transfer", "cleanup", "ftp", "secure_transfer"),
+// for the status and admin endpoints to report on and control. If the
+// worker keyed "cleanup" implements BlobRestorer, it's also registered to
+// back POST /blobs/{name}/untrash.
+func RegisterWorkerStatusEndpoints(srv *http.Server, workerMap map[string]ManagedWorker) {
+	workers.Set(workerMap)
+	if cleanup, ok := workerMap["cleanup"]; ok {
+		if restorer, ok := cleanup.(BlobRestorer); ok {
+			blobRestorer = restorer
 		}
 	}
 }
 
-// registerRoutes registers the HTTP routes
-func registerRoutes(mux *http.ServeMux) {
+// RegisterS3Client registers an S3 client's auth status for reporting
+// through the status endpoints. If client also implements ClockSkewReporter,
+// its measured clock skew is reported alongside it.
+func RegisterS3Client(client S3AuthStatus) {
+	s3AuthStatus = client
+	if reporter, ok := client.(ClockSkewReporter); ok {
+		clockSkewReporter = reporter
+	}
+}
+
+// RegisterMetrics registers a Prometheus registry to back the /metrics
+// endpoint. Until this is called, /metrics serves the legacy placeholder
+// JSON body.
+func RegisterMetrics(reg *metrics.Registry) {
+	metricsRegistry = reg
+}
+
+// RegisterPoisonLister registers the source the GET /api/v1/poison
+// endpoint lists dead-lettered messages from.
+func RegisterPoisonLister(lister PoisonLister) {
+	poisonLister = lister
+}
+
+// registerRoutes registers the HTTP routes. includeMetrics is false when
+// cfg.MetricsListenAddr has moved /metrics onto its own NewMetricsServer.
+func registerRoutes(mux *http.ServeMux, includeMetrics bool) {
 	// Health check endpoint
 	mux.HandleFunc("/health", healthHandler)
 
 	// Metrics endpoint
-	mux.HandleFunc("/metrics", metricsHandler)
+	if includeMetrics {
+		mux.HandleFunc("/metrics", metricsHandler)
+	}
 
 	// Version endpoint
 	mux.HandleFunc("/version", versionHandler)
@@ -91,26 +224,20 @@ func registerRoutes(mux *http.ServeMux) {
 	// API routes
 	mux.HandleFunc("/api/v1/status", statusHandler)
 	mux.HandleFunc("/api/v1/workers", workersHandler)
+	mux.HandleFunc("/api/v1/poison", poisonHandler)
+	mux.HandleFunc("/api/v1/admin/workers/", adminWorkersHandler)
+
+	// Blob trash lifecycle
+	mux.HandleFunc("/blobs/", blobsHandler)
 }
 
 // healthHandler handles health check requests
 func healthHandler(w http.ResponseWriter, r *http.Request) {
-	// Check worker health
 	isHealthy := true
-	if validationWorker != nil && transferWorker != nil && cleanupWorker != nil {
-		// Create a list of all active workers
-		workers := []WorkerStatus{validationWorker, transferWorker, cleanupWorker}
-		if ftpWorker != nil {
-			workers = append(workers, ftpWorker)
-		}
-		workers = append(workers, additionalWorkers...)
-		
-		// If any worker has "error" in its status, consider the system unhealthy
-		for _, worker := range workers {
-			if status := worker.Status(); len(status) >= 5 && status[:5] == "error" {
-				isHealthy = false
-				break
-			}
+	for _, worker := range workers.All() {
+		if status := worker.Status(); len(status) >= 5 && status[:5] == "error" {
+			isHealthy = false
+			break
 		}
 	}
 
@@ -124,9 +251,14 @@ func healthHandler(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
-// metricsHandler handles metrics requests
+// metricsHandler serves the Prometheus registry RegisterMetrics was given,
+// in the Prometheus text exposition format. Until a registry is
+// registered, it falls back to a placeholder JSON body.
 func metricsHandler(w http.ResponseWriter, r *http.Request) {
-	// This would be expanded to include real metrics
+	if metricsRegistry != nil {
+		metricsRegistry.Handler().ServeHTTP(w, r)
+		return
+	}
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
 	w.Write([]byte(`{"metrics":{"workers":3,"processed":0}}`))
@@ -141,22 +273,26 @@ func versionHandler(w http.ResponseWriter, r *http.Request) {
 
 // statusHandler returns the pipeline status
 func statusHandler(w http.ResponseWriter, r *http.Request) {
-	// Create a dynamic status response based on actual worker status
-	pipelineStatus := map[string]interface{}{
-		"validation_worker": getWorkerStatus(validationWorker),
-		"transfer_worker":   getWorkerStatus(transferWorker),
-		"cleanup_worker":    getWorkerStatus(cleanupWorker),
-		"last_validated":    formatLastRun(validationWorker),
-		"last_transferred":  formatLastRun(transferWorker),
-		"last_cleaned":      formatLastRun(cleanupWorker),
-	}
-	
-	// Add FTP worker status if available
-	if ftpWorker != nil {
-		pipelineStatus["ftp_worker"] = getWorkerStatus(ftpWorker)
-		pipelineStatus["last_ftp_check"] = formatLastRun(ftpWorker)
-	}
-	
+	pipelineStatus := map[string]interface{}{}
+	for name, worker := range workers.All() {
+		pipelineStatus[name+"_worker"] = worker.Status()
+		pipelineStatus["last_"+name] = formatLastRun(worker)
+	}
+
+	// Add S3 credential status if an S3 client has been registered. The
+	// token itself is never reported, only its mode and expiry.
+	if s3AuthStatus != nil {
+		pipelineStatus["s3_auth_mode"] = s3AuthStatus.AuthMode()
+		pipelineStatus["s3_auth_expiration"] = formatAuthExpiration(s3AuthStatus.AuthExpiration())
+	}
+
+	// Surface the most recently measured clock skew so operators can spot a
+	// vessel whose local clock has drifted before it starts failing signed
+	// S3 requests outright.
+	if clockSkewReporter != nil {
+		pipelineStatus["s3_clock_skew_seconds"] = clockSkewReporter.ClockSkew().Seconds()
+	}
+
 	status := map[string]interface{}{
 		"pipeline_status": pipelineStatus,
 	}
@@ -175,35 +311,20 @@ func statusHandler(w http.ResponseWriter, r *http.Request) {
 
 // workersHandler returns detailed worker status
 func workersHandler(w http.ResponseWriter, r *http.Request) {
-	workersMap := map[string]interface{}{
-		"validation": map[string]string{
-			"status":   getWorkerStatus(validationWorker),
-			"last_run": formatLastRun(validationWorker),
-		},
-		"transfer": map[string]string{
-			"status":   getWorkerStatus(transferWorker),
-			"last_run": formatLastRun(transferWorker),
-		},
-		"cleanup": map[string]string{
-			"status":   getWorkerStatus(cleanupWorker),
-			"last_run": formatLastRun(cleanupWorker),
-		},
-	}
-	
-	// Add FTP worker if available
-	if ftpWorker != nil {
-		workersMap["ftp"] = map[string]string{
-			"status":   getWorkerStatus(ftpWorker),
-			"last_run": formatLastRun(ftpWorker),
+	workersMap := map[string]interface{}{}
+	for name, worker := range workers.All() {
+		workersMap[name] = map[string]string{
+			"status":   worker.Status(),
+			"last_run": formatLastRun(worker),
 		}
 	}
-	
-	workers := map[string]interface{}{
+
+	body := map[string]interface{}{
 		"workers": workersMap,
 	}
 
 	// Marshal to JSON
-	jsonData, err := json.Marshal(workers)
+	jsonData, err := json.Marshal(body)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -214,21 +335,190 @@ func workersHandler(w http.ResponseWriter, r *http.Request) {
 	w.Write(jsonData)
 }
 
-// Helper functions
-func getWorkerStatus(w WorkerStatus) string {
-	if w == nil {
-		return "not_initialized"
+// untrashSuffix is the trailing path segment blobsHandler matches against;
+// net/http's plain ServeMux has no path-parameter support, so the blob name
+// is extracted by trimming the fixed prefix/suffix around it.
+const untrashSuffix = "/untrash"
+
+// blobsHandler handles POST /blobs/{name}/untrash, restoring a trashed blob
+// via the registered CleanupWorker.
+func blobsHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	path := strings.TrimPrefix(r.URL.Path, "/blobs/")
+	if !strings.HasSuffix(path, untrashSuffix) {
+		http.Error(w, "not found", http.StatusNotFound)
+		return
 	}
-	return w.Status()
+
+	blobName := strings.TrimSuffix(path, untrashSuffix)
+	if blobName == "" {
+		http.Error(w, "blob name is required", http.StatusBadRequest)
+		return
+	}
+
+	if blobRestorer == nil {
+		http.Error(w, "cleanup worker not available", http.StatusServiceUnavailable)
+		return
+	}
+
+	if err := blobRestorer.RestoreBlob(r.Context(), blobName); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte(fmt.Sprintf(`{"status":"restored","blob":%q}`, blobName)))
 }
 
-func formatLastRun(w WorkerStatus) string {
-	if w == nil {
-		return "never"
+// poisonHandler returns every message currently dead-lettered across the
+// pipeline's queues, for operators to inspect stuck messages.
+func poisonHandler(w http.ResponseWriter, r *http.Request) {
+	if poisonLister == nil {
+		http.Error(w, "poison queue inspection not available", http.StatusServiceUnavailable)
+		return
 	}
+
+	records, err := poisonLister.ListAll(r.Context())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(records); err != nil {
+		log.Printf("Failed to encode poison queue response: %v", err)
+	}
+}
+
+// reconfigureRequest is the POST body for .../reload: every field is
+// optional, and a zero/omitted value leaves the corresponding tunable
+// unchanged (see ManagedWorker.Reconfigure).
+type reconfigureRequest struct {
+	IntervalSeconds         int `json:"interval_seconds"`
+	BatchSize               int `json:"batch_size"`
+	RetryCount              int `json:"retry_count"`
+	VisibilityTimeoutSeconds int `json:"visibility_timeout_seconds"`
+}
+
+// adminWorkersHandler serves /api/v1/admin/workers/{name}[/pause|/resume|/reload].
+// Every request must present the shared secret configured as
+// cfg.AdminAPIToken via the X-Admin-Token header; the endpoint is disabled
+// entirely (503) if no token was configured, since these actions mutate
+// live worker behavior.
+func adminWorkersHandler(w http.ResponseWriter, r *http.Request) {
+	if adminAPIToken == "" {
+		http.Error(w, "admin API is not enabled", http.StatusServiceUnavailable)
+		return
+	}
+	if r.Header.Get("X-Admin-Token") != adminAPIToken {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	path := strings.TrimPrefix(r.URL.Path, "/api/v1/admin/workers/")
+	name := path
+	action := ""
+	if idx := strings.IndexByte(path, '/'); idx >= 0 {
+		name = path[:idx]
+		action = path[idx+1:]
+	}
+	if name == "" {
+		http.Error(w, "worker name is required", http.StatusBadRequest)
+		return
+	}
+
+	worker, ok := workers.Get(name)
+	if !ok {
+		http.Error(w, fmt.Sprintf("unknown worker %q", name), http.StatusNotFound)
+		return
+	}
+
+	switch action {
+	case "":
+		if r.Method != http.MethodGet {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		writeWorkerDetail(w, worker)
+	case "pause":
+		if r.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		worker.Pause()
+		writeWorkerDetail(w, worker)
+	case "resume":
+		if r.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		worker.Resume()
+		writeWorkerDetail(w, worker)
+	case "reload":
+		if r.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		var req reconfigureRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, fmt.Sprintf("invalid request body: %v", err), http.StatusBadRequest)
+			return
+		}
+		worker.Reconfigure(
+			time.Duration(req.IntervalSeconds)*time.Second,
+			req.BatchSize,
+			req.RetryCount,
+			time.Duration(req.VisibilityTimeoutSeconds)*time.Second,
+		)
+		writeWorkerDetail(w, worker)
+	default:
+		http.Error(w, "not found", http.StatusNotFound)
+	}
+}
+
+// writeWorkerDetail writes a worker's full current status and tunables as
+// JSON, for the admin GET/pause/resume/reload responses.
+func writeWorkerDetail(w http.ResponseWriter, worker ManagedWorker) {
+	detail := map[string]interface{}{
+		"status":      worker.Status(),
+		"last_run":    formatLastRun(worker),
+		"paused":      worker.IsPaused(),
+		"interval":    worker.Interval().String(),
+		"batch_size":  worker.BatchSize(),
+		"retry_count": worker.RetryCount(),
+	}
+	if reporter, ok := worker.(VisibilityTimeoutReporter); ok {
+		detail["visibility_timeout"] = reporter.VisibilityTimeout().String()
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(detail); err != nil {
+		log.Printf("Failed to encode worker detail response: %v", err)
+	}
+}
+
+// formatLastRun formats a worker's last run time as RFC 3339, or "never" if
+// it has never run.
+func formatLastRun(w WorkerStatus) string {
 	lastRun := w.LastRun()
 	if lastRun.IsZero() {
 		return "never"
 	}
 	return lastRun.Format(time.RFC3339)
-}
\ No newline at end of file
+}
+
+// formatAuthExpiration formats a credential expiration timestamp, returning
+// "never" for the zero time (static credentials, which don't expire).
+func formatAuthExpiration(expiration time.Time) string {
+	if expiration.IsZero() {
+		return "never"
+	}
+	return expiration.Format(time.RFC3339)
+}
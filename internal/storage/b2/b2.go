@@ -0,0 +1,130 @@
+// Package b2 registers the "b2" storage.Volume driver, backed by
+// Backblaze B2's native API rather than its S3-compatible endpoint, so
+// driver coverage matches the repo's other cloud backends (each talking to
+// its provider's own API) instead of routing B2 traffic back through the
+// s3 driver.
+package b2
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/kurin/blazer/b2"
+
+	"github.com/CAST-Intelligence/elysium-usv/internal/metrics"
+	"github.com/CAST-Intelligence/elysium-usv/internal/storage"
+)
+
+func init() {
+	storage.Register("b2", New)
+}
+
+// Volume is a storage.Volume backed by a Backblaze B2 bucket.
+type Volume struct {
+	bucket     *b2.Bucket
+	bucketName string
+}
+
+// New builds a b2 Volume from a destination's params. Recognized params:
+// bucket, account_id, and application_key, all required - B2 has no
+// ambient-credential equivalent of IAM roles or GCS's application-default
+// credentials, so both must come from config. reg is currently unused by
+// this driver; B2 isn't instrumented yet.
+func New(params map[string]string, reg *metrics.Registry) (storage.Volume, error) {
+	bucketName := params["bucket"]
+	if bucketName == "" {
+		return nil, fmt.Errorf("b2: bucket param is required")
+	}
+	accountID := params["account_id"]
+	applicationKey := params["application_key"]
+	if accountID == "" || applicationKey == "" {
+		return nil, fmt.Errorf("b2: account_id and application_key params are required")
+	}
+
+	ctx := context.Background()
+	client, err := b2.NewClient(ctx, accountID, applicationKey)
+	if err != nil {
+		return nil, fmt.Errorf("b2: failed to authenticate: %w", err)
+	}
+
+	bucket, err := client.Bucket(ctx, bucketName)
+	if err != nil {
+		return nil, fmt.Errorf("b2: failed to open bucket %s: %w", bucketName, err)
+	}
+
+	return &Volume{bucket: bucket, bucketName: bucketName}, nil
+}
+
+// Put uploads r under key, returning B2's server-computed SHA1 content hash
+// as its etag - B2's native equivalent of an S3 ETag, and unlike S3's
+// multipart "-N" digest, always a plain hash of the object's bytes
+// regardless of how many parts the upload used.
+func (v *Volume) Put(ctx context.Context, key string, r io.Reader) (string, error) {
+	obj := v.bucket.Object(key)
+	w := obj.NewWriter(ctx)
+
+	if _, err := io.Copy(w, r); err != nil {
+		w.Close()
+		return "", fmt.Errorf("b2: failed to upload %s: %w", key, err)
+	}
+	if err := w.Close(); err != nil {
+		return "", fmt.Errorf("b2: failed to commit %s: %w", key, err)
+	}
+
+	attrs, err := obj.Attrs(ctx)
+	if err != nil {
+		return "", fmt.Errorf("b2: failed to read attrs for %s: %w", key, err)
+	}
+	return attrs.SHA1, nil
+}
+
+// Head reports whether an object exists under key and, if so, its size.
+func (v *Volume) Head(ctx context.Context, key string) (bool, int64, error) {
+	attrs, err := v.bucket.Object(key).Attrs(ctx)
+	if err != nil {
+		// blazer doesn't export a typed not-found sentinel usable across its
+		// various backing transports, so fall back to matching the message -
+		// the same simplification VerifyObject already makes for S3.
+		if strings.Contains(strings.ToLower(err.Error()), "not found") ||
+			strings.Contains(strings.ToLower(err.Error()), "no such") {
+			return false, 0, nil
+		}
+		return false, 0, fmt.Errorf("b2: failed to stat %s: %w", key, err)
+	}
+	return true, attrs.Size, nil
+}
+
+// Get opens the object under key for reading.
+func (v *Volume) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	return v.bucket.Object(key).NewReader(ctx), nil
+}
+
+// Delete removes the object under key.
+func (v *Volume) Delete(ctx context.Context, key string) error {
+	if err := v.bucket.Object(key).Delete(ctx); err != nil {
+		return fmt.Errorf("b2: failed to delete %s: %w", key, err)
+	}
+	return nil
+}
+
+// Index calls cb for every object whose key starts with prefix.
+func (v *Volume) Index(ctx context.Context, prefix string, cb func(name string, mtime time.Time)) error {
+	iter := v.bucket.List(ctx, b2.ListPrefix(prefix))
+	for iter.Next() {
+		obj := iter.Object()
+		attrs, err := obj.Attrs(ctx)
+		if err != nil {
+			return fmt.Errorf("b2: failed to read attrs while listing: %w", err)
+		}
+		cb(obj.Name(), attrs.UploadTimestamp)
+	}
+	return iter.Err()
+}
+
+// Destination returns "b2://bucket/key" for audit/metadata purposes.
+func (v *Volume) Destination(key string) string {
+	return "b2://" + v.bucketName + "/" + key
+}
@@ -0,0 +1,161 @@
+// Package file registers the "file" storage.Volume driver, a local
+// filesystem backend useful for testing transfer against a real Volume
+// without any cloud credentials, and for ship-side staging destinations
+// that only need a local directory.
+package file
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/CAST-Intelligence/elysium-usv/internal/metrics"
+	"github.com/CAST-Intelligence/elysium-usv/internal/storage"
+)
+
+func init() {
+	storage.Register("file", New)
+}
+
+// Volume is a storage.Volume backed by a directory on the local filesystem.
+type Volume struct {
+	rootDir string
+}
+
+// New builds a file Volume rooted at params["root_dir"]. reg is currently
+// unused by this driver; the local filesystem backend isn't instrumented.
+func New(params map[string]string, reg *metrics.Registry) (storage.Volume, error) {
+	rootDir := params["root_dir"]
+	if rootDir == "" {
+		return nil, fmt.Errorf("file: root_dir param is required")
+	}
+	if err := os.MkdirAll(rootDir, 0o755); err != nil {
+		return nil, fmt.Errorf("file: failed to create root_dir %s: %w", rootDir, err)
+	}
+	return &Volume{rootDir: rootDir}, nil
+}
+
+// path resolves key to a path under rootDir, rejecting any key that would
+// escape it.
+func (v *Volume) path(key string) (string, error) {
+	cleaned := filepath.Clean("/" + key)
+	full := filepath.Join(v.rootDir, cleaned)
+	if !strings.HasPrefix(full, filepath.Clean(v.rootDir)+string(filepath.Separator)) {
+		return "", fmt.Errorf("file: key %q escapes root_dir", key)
+	}
+	return full, nil
+}
+
+// Put writes r to key, computing an MD5-based etag the same way S3 does for
+// single-part uploads. The write goes to a temp file in the same directory
+// and is renamed into place once complete, so a reader can never observe a
+// partially written object.
+func (v *Volume) Put(ctx context.Context, key string, r io.Reader) (string, error) {
+	dest, err := v.path(key)
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(filepath.Dir(dest), 0o755); err != nil {
+		return "", fmt.Errorf("file: failed to create directory for %s: %w", key, err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(dest), ".upload-*")
+	if err != nil {
+		return "", fmt.Errorf("file: failed to create temp file for %s: %w", key, err)
+	}
+	tmpPath := tmp.Name()
+	defer os.Remove(tmpPath)
+
+	hash := md5.New()
+	if _, err := io.Copy(io.MultiWriter(tmp, hash), r); err != nil {
+		tmp.Close()
+		return "", fmt.Errorf("file: failed to write %s: %w", key, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return "", fmt.Errorf("file: failed to close temp file for %s: %w", key, err)
+	}
+
+	if err := os.Rename(tmpPath, dest); err != nil {
+		return "", fmt.Errorf("file: failed to commit %s: %w", key, err)
+	}
+
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// Head reports whether an object exists under key and, if so, its size.
+func (v *Volume) Head(ctx context.Context, key string) (bool, int64, error) {
+	full, err := v.path(key)
+	if err != nil {
+		return false, 0, err
+	}
+	info, err := os.Stat(full)
+	if os.IsNotExist(err) {
+		return false, 0, nil
+	}
+	if err != nil {
+		return false, 0, fmt.Errorf("file: failed to stat %s: %w", key, err)
+	}
+	return true, info.Size(), nil
+}
+
+// Get opens the object under key for reading.
+func (v *Volume) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	full, err := v.path(key)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(full)
+	if err != nil {
+		return nil, fmt.Errorf("file: failed to open %s: %w", key, err)
+	}
+	return f, nil
+}
+
+// Delete removes the object under key.
+func (v *Volume) Delete(ctx context.Context, key string) error {
+	full, err := v.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(full); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("file: failed to delete %s: %w", key, err)
+	}
+	return nil
+}
+
+// Index calls cb for every object whose key starts with prefix.
+func (v *Volume) Index(ctx context.Context, prefix string, cb func(name string, mtime time.Time)) error {
+	return filepath.WalkDir(v.rootDir, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		rel, err := filepath.Rel(v.rootDir, path)
+		if err != nil {
+			return err
+		}
+		key := filepath.ToSlash(rel)
+		if !strings.HasPrefix(key, prefix) {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		cb(key, info.ModTime())
+		return nil
+	})
+}
+
+// Destination returns "file://rootDir/key" for audit/metadata purposes.
+func (v *Volume) Destination(key string) string {
+	return "file://" + filepath.Join(v.rootDir, key)
+}
@@ -0,0 +1,126 @@
+// Package gcs registers the "gcs" storage.Volume driver, backed by Google
+// Cloud Storage.
+package gcs
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"cloud.google.com/go/storage"
+	"google.golang.org/api/iterator"
+	"google.golang.org/api/option"
+
+	"github.com/CAST-Intelligence/elysium-usv/internal/metrics"
+	internalstorage "github.com/CAST-Intelligence/elysium-usv/internal/storage"
+)
+
+func init() {
+	internalstorage.Register("gcs", New)
+}
+
+// Volume is a storage.Volume backed by a GCS bucket.
+type Volume struct {
+	client     *storage.Client
+	bucketName string
+}
+
+// New builds a gcs Volume from a destination's params. Recognized params:
+// bucket (required) and credentials_file (path to a service-account JSON
+// key; if empty, application-default credentials are used). reg is
+// currently unused by this driver; GCS isn't instrumented yet.
+func New(params map[string]string, reg *metrics.Registry) (internalstorage.Volume, error) {
+	bucketName := params["bucket"]
+	if bucketName == "" {
+		return nil, fmt.Errorf("gcs: bucket param is required")
+	}
+
+	ctx := context.Background()
+	var opts []option.ClientOption
+	if credentialsFile := params["credentials_file"]; credentialsFile != "" {
+		opts = append(opts, option.WithCredentialsFile(credentialsFile))
+	}
+
+	client, err := storage.NewClient(ctx, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("gcs: failed to create client: %w", err)
+	}
+
+	return &Volume{client: client, bucketName: bucketName}, nil
+}
+
+func (v *Volume) bucket() *storage.BucketHandle {
+	return v.client.Bucket(v.bucketName)
+}
+
+// Put uploads r under key, returning the object's CRC32C checksum, in
+// decimal, as its etag.
+func (v *Volume) Put(ctx context.Context, key string, r io.Reader) (string, error) {
+	obj := v.bucket().Object(key)
+	w := obj.NewWriter(ctx)
+
+	if _, err := io.Copy(w, r); err != nil {
+		w.Close()
+		return "", fmt.Errorf("gcs: failed to upload %s: %w", key, err)
+	}
+	if err := w.Close(); err != nil {
+		return "", fmt.Errorf("gcs: failed to commit %s: %w", key, err)
+	}
+
+	return fmt.Sprintf("%d", w.Attrs().CRC32C), nil
+}
+
+// Head reports whether an object exists under key and, if so, its size.
+func (v *Volume) Head(ctx context.Context, key string) (bool, int64, error) {
+	attrs, err := v.bucket().Object(key).Attrs(ctx)
+	if err == storage.ErrObjectNotExist {
+		return false, 0, nil
+	}
+	if err != nil {
+		return false, 0, fmt.Errorf("gcs: failed to stat %s: %w", key, err)
+	}
+	return true, attrs.Size, nil
+}
+
+// Get opens the object under key for reading.
+func (v *Volume) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	r, err := v.bucket().Object(key).NewReader(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("gcs: failed to open %s: %w", key, err)
+	}
+	return r, nil
+}
+
+// Delete removes the object under key.
+func (v *Volume) Delete(ctx context.Context, key string) error {
+	if err := v.bucket().Object(key).Delete(ctx); err != nil {
+		return fmt.Errorf("gcs: failed to delete %s: %w", key, err)
+	}
+	return nil
+}
+
+// Index calls cb for every object whose key starts with prefix.
+func (v *Volume) Index(ctx context.Context, prefix string, cb func(name string, mtime time.Time)) error {
+	it := v.bucket().Objects(ctx, &storage.Query{Prefix: prefix})
+	for {
+		attrs, err := it.Next()
+		if err == iterator.Done {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("gcs: failed to list objects: %w", err)
+		}
+		cb(attrs.Name, attrs.Updated)
+	}
+}
+
+// Destination returns "gs://bucket/key" for audit/metadata purposes.
+func (v *Volume) Destination(key string) string {
+	return "gs://" + v.bucketName + "/" + key
+}
+
+// Close releases the underlying GCS client.
+func (v *Volume) Close() error {
+	return v.client.Close()
+}
@@ -0,0 +1,164 @@
+// Package localobjectstore registers the "filesystem" storage.ObjectStore
+// driver, a local directory backend that lets the validation pipeline run
+// end-to-end in tests without standing up Azurite. User-defined metadata,
+// which a plain file has no room for, is kept in a JSON sidecar file next
+// to each object.
+package localobjectstore
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/CAST-Intelligence/elysium-usv/internal/config"
+	"github.com/CAST-Intelligence/elysium-usv/internal/metrics"
+	"github.com/CAST-Intelligence/elysium-usv/internal/storage"
+)
+
+func init() {
+	storage.RegisterObjectStore("filesystem", New)
+}
+
+// metaSuffix names the sidecar file holding an object's metadata, e.g.
+// "blob.dat.meta.json" next to "blob.dat".
+const metaSuffix = ".meta.json"
+
+// ObjectStore is a storage.ObjectStore backed by a directory on the local
+// filesystem.
+type ObjectStore struct {
+	rootDir string
+}
+
+// New builds a filesystem ObjectStore rooted at cfg.LocalStoreRootDir. reg
+// is currently unused by this driver; the local filesystem backend isn't
+// instrumented.
+func New(cfg *config.Config, reg *metrics.Registry) (storage.ObjectStore, error) {
+	rootDir := cfg.LocalStoreRootDir
+	if rootDir == "" {
+		return nil, fmt.Errorf("localobjectstore: LOCAL_STORE_ROOT_DIR is required for the filesystem storage driver")
+	}
+	if err := os.MkdirAll(rootDir, 0o755); err != nil {
+		return nil, fmt.Errorf("localobjectstore: failed to create root dir %s: %w", rootDir, err)
+	}
+	return &ObjectStore{rootDir: rootDir}, nil
+}
+
+// path resolves key to a path under rootDir, rejecting any key that would
+// escape it.
+func (o *ObjectStore) path(key string) (string, error) {
+	cleaned := filepath.Clean("/" + key)
+	full := filepath.Join(o.rootDir, cleaned)
+	if !strings.HasPrefix(full, filepath.Clean(o.rootDir)+string(filepath.Separator)) {
+		return "", fmt.Errorf("localobjectstore: key %q escapes root dir", key)
+	}
+	return full, nil
+}
+
+// Get opens the object under key for reading.
+func (o *ObjectStore) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	full, err := o.path(key)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(full)
+	if err != nil {
+		return nil, fmt.Errorf("localobjectstore: failed to open %s: %w", key, err)
+	}
+	return f, nil
+}
+
+// Head reports whether the object under key exists and, if so, its size
+// and user-defined metadata.
+func (o *ObjectStore) Head(ctx context.Context, key string) (bool, int64, map[string]string, error) {
+	full, err := o.path(key)
+	if err != nil {
+		return false, 0, nil, err
+	}
+	info, err := os.Stat(full)
+	if os.IsNotExist(err) {
+		return false, 0, nil, nil
+	}
+	if err != nil {
+		return false, 0, nil, fmt.Errorf("localobjectstore: failed to stat %s: %w", key, err)
+	}
+
+	metadata, err := o.readMetadata(full)
+	if err != nil {
+		return false, 0, nil, err
+	}
+
+	return true, info.Size(), metadata, nil
+}
+
+// SetMetadata replaces the user-defined metadata on the object under key.
+func (o *ObjectStore) SetMetadata(ctx context.Context, key string, metadata map[string]string) error {
+	full, err := o.path(key)
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(metadata)
+	if err != nil {
+		return fmt.Errorf("localobjectstore: failed to marshal metadata for %s: %w", key, err)
+	}
+	if err := os.WriteFile(full+metaSuffix, data, 0o644); err != nil {
+		return fmt.Errorf("localobjectstore: failed to write metadata for %s: %w", key, err)
+	}
+	return nil
+}
+
+// Delete removes the object under key and its metadata sidecar, if any.
+func (o *ObjectStore) Delete(ctx context.Context, key string) error {
+	full, err := o.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(full); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("localobjectstore: failed to delete %s: %w", key, err)
+	}
+	if err := os.Remove(full + metaSuffix); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("localobjectstore: failed to delete metadata for %s: %w", key, err)
+	}
+	return nil
+}
+
+// List calls cb for every object whose key starts with prefix.
+func (o *ObjectStore) List(ctx context.Context, prefix string, cb func(key string, mtime time.Time) error) error {
+	return filepath.Walk(o.rootDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() || strings.HasSuffix(path, metaSuffix) {
+			return nil
+		}
+		rel, err := filepath.Rel(o.rootDir, path)
+		if err != nil {
+			return err
+		}
+		key := filepath.ToSlash(rel)
+		if !strings.HasPrefix(key, prefix) {
+			return nil
+		}
+		return cb(key, info.ModTime())
+	})
+}
+
+// readMetadata reads the sidecar file for full, if it exists.
+func (o *ObjectStore) readMetadata(full string) (map[string]string, error) {
+	data, err := os.ReadFile(full + metaSuffix)
+	if os.IsNotExist(err) {
+		return map[string]string{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("localobjectstore: failed to read metadata for %s: %w", full, err)
+	}
+	var metadata map[string]string
+	if err := json.Unmarshal(data, &metadata); err != nil {
+		return nil, fmt.Errorf("localobjectstore: failed to parse metadata for %s: %w", full, err)
+	}
+	return metadata, nil
+}
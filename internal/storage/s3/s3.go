@@ -0,0 +1,166 @@
+// Package s3 registers the "s3" storage.Volume driver, wrapping the
+// existing aws.S3Client (IAM/web-identity credential refresh, multipart
+// uploads, MD5/SHA256 integrity verification) behind the generic Volume
+// interface.
+package s3
+
+import (
+	"context"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/CAST-Intelligence/elysium-usv/internal/aws"
+	"github.com/CAST-Intelligence/elysium-usv/internal/config"
+	"github.com/CAST-Intelligence/elysium-usv/internal/metrics"
+	"github.com/CAST-Intelligence/elysium-usv/internal/storage"
+)
+
+func init() {
+	storage.Register("s3", New)
+}
+
+// defaultMaxClockSkew matches config.Load's default for MAX_CLOCK_SKEW;
+// Volumes built from DESTINATIONS_JSON params don't go through config.Load,
+// so New must supply the same default itself.
+const defaultMaxClockSkew = 600 * time.Second
+
+// Volume wraps an *aws.S3Client to satisfy storage.Volume.
+type Volume struct {
+	client *aws.S3Client
+}
+
+// New builds an s3 Volume from a destination's params. Recognized params:
+// endpoint_url, access_key_id, secret_access_key, region, bucket, auth_mode,
+// role_arn, web_identity_token_file, role_session_name, part_size,
+// upload_concurrency, max_clock_skew, allow_skewed_clock. All but
+// bucket/region are optional. endpoint_url also covers S3-compatible
+// ground-station backends (MinIO, Wasabi, Ceph RGW) that speak the same
+// signed-request protocol; they don't need a driver of their own.
+func New(params map[string]string, reg *metrics.Registry) (storage.Volume, error) {
+	cfg := &config.Config{
+		AWSEndpointURL:          params["endpoint_url"],
+		AWSAccessKey:            params["access_key_id"],
+		AWSSecretKey:            params["secret_access_key"],
+		AWSRegion:               params["region"],
+		AWSBucketName:           params["bucket"],
+		AWSAuthMode:             params["auth_mode"],
+		AWSRoleARN:              params["role_arn"],
+		AWSWebIdentityTokenFile: params["web_identity_token_file"],
+		AWSRoleSessionName:      params["role_session_name"],
+		MaxClockSkew:            defaultMaxClockSkew,
+	}
+	if v := params["part_size"]; v != "" {
+		if partSize, err := strconv.ParseInt(v, 10, 64); err == nil {
+			cfg.S3UploadPartSize = partSize
+		}
+	}
+	if v := params["upload_concurrency"]; v != "" {
+		if concurrency, err := strconv.Atoi(v); err == nil {
+			cfg.S3UploadConcurrency = concurrency
+		}
+	}
+	if v := params["max_clock_skew"]; v != "" {
+		if skew, err := time.ParseDuration(v); err == nil {
+			cfg.MaxClockSkew = skew
+		}
+	}
+	if v := params["allow_skewed_clock"]; v != "" {
+		if allow, err := strconv.ParseBool(v); err == nil {
+			cfg.AllowSkewedClock = allow
+		}
+	}
+
+	client, err := aws.NewS3Client(cfg, reg)
+	if err != nil {
+		return nil, err
+	}
+	return &Volume{client: client}, nil
+}
+
+// Put uploads r under key with no expected-checksum precondition.
+func (v *Volume) Put(ctx context.Context, key string, r io.Reader) (string, error) {
+	etag, _, err := v.client.UploadObject(ctx, key, r, "", nil)
+	return etag, err
+}
+
+// PutWithChecksum uploads r under key, verifying it against
+// expectedMD5Hex (if non-empty) and returning the server-verified SHA256
+// checksum alongside the ETag. destMetadata is attached to the object as
+// its S3 user metadata. Destinations backed by aws.S3Client (just this
+// driver, currently) implement this to preserve the integrity verification
+// transfer.TransferValidatedBlob relies on; it's not part of the base
+// Volume interface since most backends have no equivalent.
+func (v *Volume) PutWithChecksum(ctx context.Context, key string, r io.Reader, expectedMD5Hex string, destMetadata map[string]string) (string, string, error) {
+	return v.client.UploadObject(ctx, key, r, expectedMD5Hex, destMetadata)
+}
+
+// PutResumable uploads r under key exactly like PutWithChecksum, but
+// threads checkpoint through to the wrapped client so a multipart upload
+// interrupted partway through resumes from its last completed part instead
+// of restarting. Destinations backed by aws.S3Client implement this so
+// transfer.TransferValidatedBlob can opt a destination into resumability
+// without it being part of the base Volume interface.
+func (v *Volume) PutResumable(ctx context.Context, key string, r io.Reader, expectedMD5Hex string, destMetadata map[string]string, checkpoint aws.UploadCheckpoint) (string, string, error) {
+	return v.client.UploadObjectResumable(ctx, key, r, expectedMD5Hex, destMetadata, checkpoint)
+}
+
+// PartSize returns the size the wrapped client splits multipart uploads
+// into, so a caller resuming an interrupted upload can compute which byte
+// offset to resume downloading the source from.
+func (v *Volume) PartSize() int64 {
+	return v.client.PartSize()
+}
+
+// ListMultipartUploads lists every multipart upload currently in progress
+// against this destination's bucket, for transfer.ReconcileStaleUploads.
+func (v *Volume) ListMultipartUploads(ctx context.Context) ([]aws.MultipartUploadInfo, error) {
+	return v.client.ListMultipartUploads(ctx)
+}
+
+// AbortMultipartUpload aborts an in-progress multipart upload, for
+// transfer.ReconcileStaleUploads to clean up uploads too old to resume.
+func (v *Volume) AbortMultipartUpload(ctx context.Context, key, uploadID string) error {
+	return v.client.AbortMultipartUpload(ctx, key, uploadID)
+}
+
+// Head reports whether an object exists under key and, if so, its size.
+func (v *Volume) Head(ctx context.Context, key string) (bool, int64, error) {
+	return v.client.StatObject(ctx, key)
+}
+
+// Get opens the object under key for reading.
+func (v *Volume) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	return v.client.GetObject(ctx, key)
+}
+
+// Delete removes the object under key.
+func (v *Volume) Delete(ctx context.Context, key string) error {
+	return v.client.DeleteObject(ctx, key)
+}
+
+// Index calls cb for every object whose key starts with prefix.
+func (v *Volume) Index(ctx context.Context, prefix string, cb func(name string, mtime time.Time)) error {
+	return v.client.ListObjects(ctx, prefix, cb)
+}
+
+// AuthMode and AuthExpiration pass through to the wrapped aws.S3Client so
+// this driver can still back the server package's S3AuthStatus reporting
+// when it's the first configured destination.
+func (v *Volume) AuthMode() string           { return v.client.AuthMode() }
+func (v *Volume) AuthExpiration() time.Time  { return v.client.AuthExpiration() }
+
+// ClockSkew passes through to the wrapped aws.S3Client so this driver can
+// also back the server package's ClockSkewReporter reporting.
+func (v *Volume) ClockSkew() time.Duration { return v.client.ClockSkew() }
+
+// Close stops the wrapped client's background credential refresher, if any.
+func (v *Volume) Close() {
+	v.client.Close()
+}
+
+// Destination returns "bucket/key", the same format transfer.go has always
+// recorded in a blob's s3destination metadata.
+func (v *Volume) Destination(key string) string {
+	return v.client.BucketName() + "/" + key
+}
@@ -0,0 +1,117 @@
+// Package metadata implements the key-name translation the transfer path
+// needs between the metadata dialects of the systems it moves blobs
+// between: Azure Blob Storage, whose metadata keys must be valid C#
+// identifiers (no hyphens, dots, or leading digits), and S3, which accepts
+// arbitrary bytes on write but always folds keys to lower case on read.
+// Without an explicit, reversible mapping, vessel-supplied fields like
+// "vessel-id" or "sensor.type" get silently dropped by Azure's SDK or
+// renamed beyond recognition by the time they reach S3.
+package metadata
+
+import (
+	"strconv"
+	"strings"
+)
+
+// escapeGuard is a zero-width marker Canonicalize prepends to a key that
+// would otherwise start with a digit, which C# identifiers (and therefore
+// Azure metadata keys) can't do. It can never collide with a real escaped
+// byte because "XD" isn't valid hexadecimal, so Decanonicalize can strip it
+// unambiguously.
+const escapeGuard = "_XD"
+
+// Canonicalize rewrites an arbitrary metadata key into a valid Azure blob
+// metadata key. ASCII letters and digits pass through unchanged; every
+// other byte - including a literal underscore, so the escape introducer is
+// never ambiguous with an unescaped character - is replaced with "_"
+// followed by its uppercase hex value. For example "vessel-id" becomes
+// "vessel_2Did", and "sensor.type" becomes "sensor_2Etype".
+func Canonicalize(key string) string {
+	var b strings.Builder
+	if len(key) > 0 && key[0] >= '0' && key[0] <= '9' {
+		b.WriteString(escapeGuard)
+	}
+	for i := 0; i < len(key); i++ {
+		c := key[i]
+		if isPlainIdentByte(c) {
+			b.WriteByte(c)
+			continue
+		}
+		b.WriteByte('_')
+		if c < 0x10 {
+			b.WriteByte('0')
+		}
+		b.WriteString(strings.ToUpper(strconv.FormatUint(uint64(c), 16)))
+	}
+	return b.String()
+}
+
+// isPlainIdentByte reports whether c can appear unescaped in a
+// canonicalized key: ASCII letters and digits, but not underscore - see
+// Canonicalize's doc comment for why underscore is always escaped.
+func isPlainIdentByte(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+}
+
+// Decanonicalize reverses Canonicalize, recovering the original key from
+// its Azure-safe form. A malformed escape sequence (truncated, or not valid
+// hex) is left in place rather than erroring: a metadata key is a
+// best-effort convenience for downstream tooling, and a garbled key should
+// still round-trip to something legible enough to debug rather than
+// aborting an otherwise-successful transfer.
+func Decanonicalize(key string) string {
+	key = strings.TrimPrefix(key, escapeGuard)
+	var b strings.Builder
+	for i := 0; i < len(key); i++ {
+		if key[i] == '_' && i+2 < len(key) {
+			if n, err := strconv.ParseUint(key[i+1:i+3], 16, 8); err == nil {
+				b.WriteByte(byte(n))
+				i += 2
+				continue
+			}
+		}
+		b.WriteByte(key[i])
+	}
+	return b.String()
+}
+
+// MetadataMapper translates metadata between Azure's canonicalized key
+// space and S3's, for the two directions the transfer path needs: reading
+// metadata back off an Azure blob (FromAzure) and building the Metadata map
+// for an S3 upload from it (ToS3). It holds no state; it's a type rather
+// than bare functions so a future destination with its own naming rules
+// (e.g. GCS's stricter character set) has an obvious place to add a method
+// without every caller re-deriving the right order of operations.
+type MetadataMapper struct{}
+
+// FromAzure reads a blob's raw Azure metadata - keyed by the canonicalized,
+// identifier-safe form Canonicalize produces - and returns it keyed by each
+// field's original, human-readable name. A blob carrying "vessel_2Did"
+// comes back keyed "vessel-id". Nil values (Azure's SDK represents an
+// absent value this way) are skipped.
+func (MetadataMapper) FromAzure(azureMetadata map[string]*string) map[string]string {
+	out := make(map[string]string, len(azureMetadata))
+	for k, v := range azureMetadata {
+		if v == nil {
+			continue
+		}
+		out[Decanonicalize(k)] = *v
+	}
+	return out
+}
+
+// ToS3 translates metadata already keyed by original field name (as
+// FromAzure returns it) into the form to hand an S3 PutObject/
+// CreateMultipartUpload's Metadata field. S3 itself places no restriction
+// on metadata key characters going in, but always returns them lower-cased
+// on a subsequent read (the AWS SDK folds x-amz-meta-* header names to
+// lower case), so keys are lower-cased here on write too - otherwise a
+// round trip through S3 would silently change a key's case out from under
+// whatever compares it against the original.
+func (MetadataMapper) ToS3(metadata map[string]string) map[string]string {
+	out := make(map[string]string, len(metadata))
+	for k, v := range metadata {
+		out[strings.ToLower(k)] = v
+	}
+	return out
+}
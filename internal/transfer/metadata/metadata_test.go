@@ -0,0 +1,92 @@
+package metadata
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCanonicalizeDecanonicalizeRoundTrip(t *testing.T) {
+	cases := []string{
+		"vessel-id",
+		"sensor.type",
+		"plainkey",
+		"9startsWithDigit",
+		"has_underscore",
+		"",
+	}
+	for _, key := range cases {
+		got := Decanonicalize(Canonicalize(key))
+		if got != key {
+			t.Errorf("round trip for %q: Canonicalize = %q, Decanonicalize back = %q", key, Canonicalize(key), got)
+		}
+	}
+}
+
+func TestCanonicalizeKnownValues(t *testing.T) {
+	cases := map[string]string{
+		"vessel-id":   "vessel_2Did",
+		"sensor.type": "sensor_2Etype",
+	}
+	for key, want := range cases {
+		if got := Canonicalize(key); got != want {
+			t.Errorf("Canonicalize(%q) = %q, want %q", key, got, want)
+		}
+	}
+}
+
+func TestDecanonicalizeMalformedEscapeLeftInPlace(t *testing.T) {
+	// A truncated or non-hex escape sequence should round-trip to something
+	// legible rather than erroring, per Decanonicalize's doc comment.
+	got := Decanonicalize("foo_ZZbar")
+	want := "foo_ZZbar"
+	if got != want {
+		t.Errorf("Decanonicalize(%q) = %q, want %q", "foo_ZZbar", got, want)
+	}
+}
+
+func TestMetadataMapperFromAzureSkipsNilValues(t *testing.T) {
+	present := "present-value"
+	azureMetadata := map[string]*string{
+		"vessel_2Did":   &present,
+		"sensor_2Etype": nil,
+	}
+
+	got := MetadataMapper{}.FromAzure(azureMetadata)
+
+	if len(got) != 1 {
+		t.Fatalf("FromAzure() = %v, want exactly one entry", got)
+	}
+	if got["vessel-id"] != present {
+		t.Errorf("FromAzure()[%q] = %q, want %q", "vessel-id", got["vessel-id"], present)
+	}
+}
+
+func TestMetadataMapperToS3LowercasesKeys(t *testing.T) {
+	got := MetadataMapper{}.ToS3(map[string]string{"Vessel-ID": "abc"})
+	if got["vessel-id"] != "abc" {
+		t.Errorf("ToS3() = %v, want lower-cased key \"vessel-id\"", got)
+	}
+}
+
+func TestMetadataMapperRoundTripThroughAzureAndS3(t *testing.T) {
+	original := map[string]string{
+		"vessel-id":   "EKI123",
+		"sensor.type": "gps",
+	}
+
+	azureMetadata := make(map[string]*string, len(original))
+	for k, v := range original {
+		v := v
+		azureMetadata[Canonicalize(k)] = &v
+	}
+
+	mapper := MetadataMapper{}
+	fromAzure := mapper.FromAzure(azureMetadata)
+	s3Metadata := mapper.ToS3(fromAzure)
+
+	for k, want := range original {
+		if got := s3Metadata[strings.ToLower(k)]; got != want {
+			t.Errorf("round trip for %q: got %q, want %q", k, got, want)
+		}
+	}
+}
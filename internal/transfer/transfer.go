@@ -2,16 +2,67 @@ package transfer
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"log"
 	"path/filepath"
 	"strings"
 	"time"
 
-	"github.com/CAST-Intelligence/elysium-usv/internal/aws"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
 	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob"
+	"github.com/CAST-Intelligence/elysium-usv/internal/aws"
+	"github.com/CAST-Intelligence/elysium-usv/internal/storage"
+	"github.com/CAST-Intelligence/elysium-usv/internal/transfer/metadata"
 )
 
+// reservedMetadataKeys are the blob metadata fields the transfer pipeline
+// itself reads or writes for bookkeeping rather than vessel-supplied
+// content; they're excluded from the custom metadata forwarded to a
+// destination so a blob's transfer bookkeeping never gets mistaken for
+// payload metadata.
+var reservedMetadataKeys = map[string]bool{
+	"checksum":            true,
+	"vesselid":            true,
+	"validationtimestamp": true,
+	"validationstatus":    true,
+	"transferstatus":      true,
+	"transferstate":       true,
+	"transfertimestamp":   true,
+	"uploadid":            true,
+	"partsjson":           true,
+	"s3destination":       true,
+	"s3etag":              true,
+	"contentmd5":          true,
+	"s3checksumsha256":    true,
+	"destinations":        true,
+}
+
+// Transfer state values for the "transferstate" blob metadata field. A
+// transfer to a resumable destination moves through these in order as
+// TransferValidatedBlob progresses; ReconcileStaleUploads matches on
+// StateUploading to find transfers that died mid-upload and need resuming
+// or aborting.
+const (
+	StatePending         = "pending"
+	StateDownloading     = "downloading"
+	StateUploading       = "uploading"
+	StateVerifying       = "verifying"
+	StateTransferred     = "transferred"
+	StateMetadataUpdated = "metadataupdated"
+)
+
+// StatusVerificationFailed is recorded in the "transferstatus" metadata
+// field (distinct from "transferstate" above) when a destination's
+// server-side checksum re-verification comes back different from what was
+// computed locally before upload - see aws.ErrChecksumMismatch. It marks the
+// blob as needing operator attention rather than a routine retry, since a
+// checksum mismatch after a successful-looking upload points at data
+// corruption somewhere in the path, not a transient failure.
+const StatusVerificationFailed = "verification_failed"
+
 // BlobInfo represents information about a blob
 type BlobInfo struct {
 	ContainerName  string
@@ -20,22 +71,154 @@ type BlobInfo struct {
 	Checksum       string
 	ValidationTime string
 	Metadata       map[string]string
+	// RawMetadata is the blob's metadata exactly as Azure returned it (still
+	// canonicalized, unlike Metadata above), captured at the same read that
+	// produced ETag. TransferValidatedBlob bases its final metadata update on
+	// this snapshot rather than a fresh GetProperties call, so a concurrent
+	// external mutation during the transfer isn't silently merged in - see
+	// TransferValidatedBlob's final SetMetadata call.
+	RawMetadata  map[string]*string
+	ETag         azcore.ETag
+	LastModified time.Time
+}
+
+// RaceWindowError is returned by TransferValidatedBlob when a blob's
+// LastModified is too recent to trust that whatever wrote it has finished:
+// transferring it now risks reading a partial write. It's a distinct type
+// (rather than a plain fmt.Errorf) so a caller like TransferWorker can tell
+// "try again shortly, nothing is wrong" apart from a real failure and skip
+// it without counting it against the message's delivery count.
+type RaceWindowError struct {
+	BlobName     string
+	LastModified time.Time
+	RaceWindow   time.Duration
+}
+
+func (e *RaceWindowError) Error() string {
+	return fmt.Sprintf("blob %s was last modified %s ago, inside the %s race window; skipping until it ages out", e.BlobName, time.Since(e.LastModified), e.RaceWindow)
+}
+
+// PreconditionFailedError is returned by TransferValidatedBlob when an
+// IfMatch precondition on the source blob fails, meaning something else
+// mutated it between the read that captured its ETag and the operation that
+// depended on that ETag still being current. Like RaceWindowError, it's a
+// distinct type so the caller can retry rather than treating it as an
+// ordinary transfer failure. Err is the underlying Azure error; Unwrap
+// exposes it for errors.Is/As.
+type PreconditionFailedError struct {
+	BlobName string
+	Op       string
+	Err      error
 }
 
-// TransferValidatedBlob transfers a validated blob to S3
+func (e *PreconditionFailedError) Error() string {
+	return fmt.Sprintf("precondition failed during %s of blob %s: %v", e.Op, e.BlobName, e.Err)
+}
+
+func (e *PreconditionFailedError) Unwrap() error {
+	return e.Err
+}
+
+// isPreconditionFailedErr matches the same way the rest of the codebase
+// detects Azure Storage error codes (see worker.isQueueAlreadyExistsError),
+// rather than pulling in the bloberror subpackage for a single check.
+func isPreconditionFailedErr(err error) bool {
+	return err != nil && strings.Contains(err.Error(), "ConditionNotMet")
+}
+
+// Note on design: this pipeline depends on storage.Volume plus the optional
+// capability interfaces below (checksumPutter, resumablePutter,
+// staleUploadLister, destinationNamer) rather than a single dedicated
+// transfer.ObjectSink interface covering PutObject/InitMultipart/UploadPart/
+// CompleteMultipart/HeadObject/BuildKey. storage.Volume already existed as
+// the one abstraction every destination driver (s3, gcs, file, b2, null)
+// implements, and multipart resumability, checksum verification, and
+// stale-upload listing are each only available from a subset of those
+// drivers - folding all of it into one interface would force drivers like
+// gcs/file to implement methods they can't meaningfully support. This is a
+// deliberate deviation from the ObjectSink shape originally requested.
+//
+// checksumPutter is implemented by storage.Volume backends (currently only
+// the s3 driver) that can verify an upload against a caller-supplied
+// expected MD5 and return a server-verified SHA256 checksum. Volume.Put
+// itself carries no checksum parameters since most backends have no
+// equivalent, so this integrity-verification feature is opted into per
+// destination via a type assertion instead.
+type checksumPutter interface {
+	PutWithChecksum(ctx context.Context, key string, r io.Reader, expectedMD5Hex string, destMetadata map[string]string) (etag, sha256Checksum string, err error)
+}
+
+// resumablePutter is implemented by storage.Volume backends (currently only
+// the s3 driver) that can checkpoint and resume an in-flight multipart
+// upload. Only the first configured destination is transferred through
+// this path (see TransferValidatedBlob), matching the precedent set by the
+// single "s3destination"/"s3etag" legacy metadata fields: the resumable
+// state machine lives in the source blob's own metadata, and tracking more
+// than one in-flight upload id per blob would need a field per destination
+// for no real benefit, since USV deployments configure exactly one
+// satellite-facing S3 destination.
+type resumablePutter interface {
+	PutResumable(ctx context.Context, key string, r io.Reader, expectedMD5Hex string, destMetadata map[string]string, checkpoint aws.UploadCheckpoint) (etag, sha256Checksum string, err error)
+	PartSize() int64
+}
+
+// staleUploadLister is implemented by storage.Volume backends that can
+// enumerate and abort their own in-progress multipart uploads, for
+// ReconcileStaleUploads.
+type staleUploadLister interface {
+	ListMultipartUploads(ctx context.Context) ([]aws.MultipartUploadInfo, error)
+	AbortMultipartUpload(ctx context.Context, key, uploadID string) error
+}
+
+// destinationNamer is implemented by Volume backends that can render a
+// human-readable location string (e.g. "bucket/key" or "gs://bucket/key")
+// for a key, used in the audit trail and blob metadata.
+type destinationNamer interface {
+	Destination(key string) string
+}
+
+// destinationResult records the outcome of transferring a blob to a single
+// Volume, for inclusion in the "destinations" metadata field.
+type destinationResult struct {
+	Destination    string `json:"destination"`
+	ETag           string `json:"etag,omitempty"`
+	ChecksumSHA256 string `json:"checksum_sha256,omitempty"`
+}
+
+// TransferValidatedBlob transfers a validated blob to every configured
+// storage.Volume destination. Each destination gets its own download
+// stream from Azure (rather than buffering the blob once and reusing it)
+// so transfer keeps behaving the same way it always has for a single S3
+// destination: nothing is held in memory beyond the upload part size.
 func TransferValidatedBlob(
 	ctx context.Context,
 	blobClient *azblob.Client,
-	s3Client *aws.S3Client,
+	volumes []storage.Volume,
 	containerName string,
 	blobName string,
+	raceWindow time.Duration,
 ) error {
+	if len(volumes) == 0 {
+		return fmt.Errorf("no storage destinations configured")
+	}
+
 	// Get blob info
 	blobInfo, err := getBlobInfo(ctx, blobClient, containerName, blobName)
 	if err != nil {
 		return fmt.Errorf("failed to get blob info: %w", err)
 	}
 
+	// A blob modified inside the race window might still be mid-write: the
+	// validation worker could have just finished stamping its metadata while
+	// bytes are still landing, or another transfer attempt could already be
+	// in flight. Skip it and let the caller requeue rather than risk
+	// uploading a partial blob or clobbering a concurrent update - this
+	// mirrors the grace period Keepstore's S3 volume applies before trusting
+	// an object's mtime.
+	if raceWindow > 0 && !blobInfo.LastModified.IsZero() && time.Since(blobInfo.LastModified) < raceWindow {
+		return &RaceWindowError{BlobName: blobName, LastModified: blobInfo.LastModified, RaceWindow: raceWindow}
+	}
+
 	// Check if the blob has been validated - case insensitive check
 	validationStatus := ""
 	for k, v := range blobInfo.Metadata {
@@ -48,16 +231,6 @@ func TransferValidatedBlob(
 		return fmt.Errorf("blob has not been validated or validation failed")
 	}
 
-	// Download the blob
-	containerClient := blobClient.ServiceClient().NewContainerClient(containerName)
-	blobItemClient := containerClient.NewBlobClient(blobName)
-	
-	// Download the blob
-	response, err := blobItemClient.DownloadStream(ctx, nil)
-	if err != nil {
-		return fmt.Errorf("failed to download blob: %w", err)
-	}
-
 	// Extract vessel ID from metadata or blob name - case insensitive check
 	vesselID := ""
 	for k, v := range blobInfo.Metadata {
@@ -76,62 +249,552 @@ func TransferValidatedBlob(
 		}
 	}
 
-	// Build the S3 object key
-	// For S3, we'll use the format: vesselID/data/filename
+	// Build the storage key. For S3 this has always been the format:
+	// vesselID/data/filename; that format is shared across every backend
+	// so the same blob maps to the same logical path regardless of driver.
 	fileName := filepath.Base(blobName)
-	s3Key := aws.BuildObjectKey(vesselID, fileName)
+	key := storage.BuildObjectKey(vesselID, fileName)
 
-	// Upload to S3 and get the ETag (MD5 hash)
-	etag, err := s3Client.UploadObject(ctx, s3Key, response.Body)
+	// Forward whatever vessel- or validation-supplied metadata isn't one of
+	// the pipeline's own bookkeeping fields, translated into S3's key
+	// convention, so e.g. a blob's "vessel-id" field reaches its S3 object
+	// as "vessel-id" rather than being dropped because Azure could only
+	// store it as the canonicalized "vessel_2Did".
+	mapper := metadata.MetadataMapper{}
+	customMetadata := make(map[string]string, len(blobInfo.Metadata))
+	for k, v := range blobInfo.Metadata {
+		if !reservedMetadataKeys[strings.ToLower(k)] {
+			customMetadata[k] = v
+		}
+	}
+	destMetadata := mapper.ToS3(customMetadata)
+
+	containerClient := blobClient.ServiceClient().NewContainerClient(containerName)
+	blobItemClient := containerClient.NewBlobClient(blobName)
+
+	// blobETag tracks the source blob's current ETag as transferToVolume
+	// updates it (every transfer-state write gives the blob a new one), so
+	// each destination's download is conditioned on the version this
+	// function actually expects to be downloading rather than a value
+	// that's gone stale because of the pipeline's own bookkeeping writes.
+	blobETag := blobInfo.ETag
+	results := make([]destinationResult, 0, len(volumes))
+	for i, volume := range volumes {
+		result, newETag, err := transferToVolume(ctx, blobItemClient, blobName, volume, key, blobInfo.Checksum, destMetadata, blobETag, i == 0)
+		if err != nil {
+			var precondErr *PreconditionFailedError
+			if errors.As(err, &precondErr) {
+				return err
+			}
+			return fmt.Errorf("failed to transfer to destination: %w", err)
+		}
+		blobETag = newETag
+		results = append(results, result)
+	}
+
+	// Update blob metadata to indicate successful transfer, starting from the
+	// metadata snapshot taken before this function made any changes (see
+	// BlobInfo.RawMetadata) rather than a fresh GetProperties call here: the
+	// download/upload/verify loop above can run for minutes, and a re-fetch
+	// immediately before SetMetadata would only guard the sub-millisecond gap
+	// between that read and this write, silently merging in anything an
+	// external writer changed during the whole window instead of tripping
+	// the IfMatch precondition below.
+	updatedMetadata := map[string]*string{}
+	for k, v := range blobInfo.RawMetadata {
+		updatedMetadata[k] = v
+	}
+
+	transferredStatus := "transferred"
+	timestamp := getCurrentTimestamp()
+	updatedMetadata["transferstatus"] = &transferredStatus
+	updatedMetadata["transfertimestamp"] = &timestamp
+
+	// The resumable state machine (see transferToVolume) is done once every
+	// destination has reported transferred; clear its bookkeeping fields
+	// rather than leaving a completed upload id around for
+	// ReconcileStaleUploads to puzzle over.
+	metadataUpdatedState := StateMetadataUpdated
+	updatedMetadata["transferstate"] = &metadataUpdatedState
+	delete(updatedMetadata, "uploadid")
+	delete(updatedMetadata, "partsjson")
+
+	// Keep recording the legacy single-destination fields from the first
+	// result so CleanupWorker's audit trail (which still only knows about
+	// one "s3destination") keeps working unchanged.
+	first := results[0]
+	updatedMetadata["s3destination"] = &first.Destination
+	if first.ETag != "" {
+		// s3etag is an opaque integrity token, not a content MD5: for a
+		// multipart upload it's md5(concat(part MD5s))-N, which only proves
+		// the object still matches what this specific S3 upload produced,
+		// not the original blob's content hash. contentmd5 below carries the
+		// one value downstream tools should actually compare against.
+		updatedMetadata["s3etag"] = &first.ETag
+	}
+	if blobInfo.Checksum != "" {
+		updatedMetadata["contentmd5"] = &blobInfo.Checksum
+	}
+	if first.ChecksumSHA256 != "" {
+		updatedMetadata["s3checksumsha256"] = &first.ChecksumSHA256
+	}
+
+	if len(results) > 1 {
+		destinationsJSON, err := json.Marshal(results)
+		if err != nil {
+			return fmt.Errorf("failed to marshal destination results: %w", err)
+		}
+		destinationsStr := string(destinationsJSON)
+		updatedMetadata["destinations"] = &destinationsStr
+	}
+
+	// Set the metadata, conditioned on blobETag - the ETag threaded through
+	// every write this function itself made (see transferToVolume) - rather
+	// than a value re-fetched just before this call, so the precondition
+	// actually covers everything since this function started, not just the
+	// instant before this write.
+	setMetadataOpts := &azblob.SetMetadataOptions{}
+	if blobETag != "" {
+		setMetadataOpts.AccessConditions = &azblob.AccessConditions{
+			ModifiedAccessConditions: &azblob.ModifiedAccessConditions{IfMatch: &blobETag},
+		}
+	}
+	_, err = blobItemClient.SetMetadata(ctx, updatedMetadata, setMetadataOpts)
+	if err != nil {
+		if isPreconditionFailedErr(err) {
+			return &PreconditionFailedError{BlobName: blobName, Op: "final metadata update", Err: err}
+		}
+		return fmt.Errorf("failed to update blob metadata: %w", err)
+	}
+
+	return nil
+}
+
+// transferToVolume downloads a fresh copy of the blob and uploads it to a
+// single destination, verifying integrity when the destination supports it.
+// resumable is true only for the first configured destination (see
+// TransferValidatedBlob); only that destination drives the
+// Pending->Downloading->Uploading->Verifying->Transferred state machine
+// checkpointed in the blob's own metadata, so an interrupted upload resumes
+// on the next attempt instead of re-uploading the whole object.
+func transferToVolume(
+	ctx context.Context,
+	blobItemClient *azblob.BlobClient,
+	blobName string,
+	volume storage.Volume,
+	key string,
+	expectedMD5Hex string,
+	destMetadata map[string]string,
+	blobETag azcore.ETag,
+	resumable bool,
+) (destinationResult, azcore.ETag, error) {
+	putter, supportsResume := volume.(resumablePutter)
+
+	var checkpoint *blobCheckpoint
+	var resumeUploadID string
+	var resumeParts []aws.CompletedUploadPart
+	if resumable && supportsResume {
+		checkpoint = newBlobCheckpoint(ctx, blobItemClient)
+		resumeUploadID, resumeParts = checkpoint.Resume()
+	}
+
+	if resumeUploadID == "" && checkpoint != nil {
+		if newETag, err := setTransferState(ctx, blobItemClient, StateDownloading); err != nil {
+			log.Printf("Warning: failed to record transfer state for %s: %v", key, err)
+		} else {
+			blobETag = newETag
+		}
+	}
+
+	downloadOpts := &azblob.DownloadStreamOptions{}
+	if blobETag != "" {
+		downloadOpts.AccessConditions = &azblob.AccessConditions{
+			ModifiedAccessConditions: &azblob.ModifiedAccessConditions{IfMatch: &blobETag},
+		}
+	}
+	if resumeUploadID != "" {
+		// Resuming: the bytes behind every already-completed part were
+		// already uploaded on a prior attempt, so skip straight past them
+		// rather than re-downloading and discarding them.
+		offset := int64(len(resumeParts)) * putter.PartSize()
+		downloadOpts.Range = azblob.HTTPRange{Offset: offset}
+		log.Printf("Resuming transfer of %s: upload %s already has %d parts, continuing download from byte %d", key, resumeUploadID, len(resumeParts), offset)
+	}
+
+	response, err := blobItemClient.DownloadStream(ctx, downloadOpts)
 	if err != nil {
-		return fmt.Errorf("failed to upload to S3: %w", err)
+		if isPreconditionFailedErr(err) {
+			return destinationResult{}, blobETag, &PreconditionFailedError{BlobName: blobName, Op: "download", Err: err}
+		}
+		return destinationResult{}, blobETag, fmt.Errorf("failed to download blob: %w", err)
 	}
 
-	// Verify the upload was successful
-	exists, err := s3Client.VerifyObject(ctx, s3Key)
+	var etag, sha256Checksum string
+	if checkpoint != nil {
+		if resumeUploadID == "" {
+			if newETag, err := setTransferState(ctx, blobItemClient, StateUploading); err != nil {
+				log.Printf("Warning: failed to record transfer state for %s: %v", key, err)
+			} else {
+				blobETag = newETag
+			}
+		}
+		etag, sha256Checksum, err = putter.PutResumable(ctx, key, response.Body, expectedMD5Hex, destMetadata, checkpoint)
+	} else if csPutter, ok := volume.(checksumPutter); ok {
+		// Upload, passing through the MD5 the upstream system already
+		// computed so the destination rejects the upload outright if the
+		// bytes were corrupted in transit, and requesting a server-side
+		// SHA256 checksum so byte-for-byte integrity is verified rather
+		// than assumed from a successful upload.
+		etag, sha256Checksum, err = csPutter.PutWithChecksum(ctx, key, response.Body, expectedMD5Hex, destMetadata)
+	} else {
+		etag, err = volume.Put(ctx, key, response.Body)
+	}
+	if err != nil {
+		if errors.Is(err, aws.ErrChecksumMismatch) {
+			if newETag, markErr := setTransferStatus(ctx, blobItemClient, StatusVerificationFailed); markErr != nil {
+				log.Printf("Warning: failed to record verification failure for %s: %v", key, markErr)
+			} else {
+				blobETag = newETag
+			}
+		}
+		return destinationResult{}, blobETag, fmt.Errorf("failed to upload: %w", err)
+	}
+
+	if checkpoint != nil {
+		if newETag, err := setTransferState(ctx, blobItemClient, StateVerifying); err != nil {
+			log.Printf("Warning: failed to record transfer state for %s: %v", key, err)
+		} else {
+			blobETag = newETag
+		}
+	}
+
+	// Verify the upload landed
+	exists, size, err := volume.Head(ctx, key)
 	if err != nil || !exists {
-		return fmt.Errorf("failed to verify S3 upload: %w", err)
+		return destinationResult{}, blobETag, fmt.Errorf("failed to verify upload: %w", err)
+	}
+	if size == 0 {
+		log.Printf("Warning: uploaded object %s is empty", key)
 	}
-	
-	// Check if we got a valid ETag
+
 	if etag == "" {
-		log.Printf("Warning: No ETag received for %s", s3Key)
+		log.Printf("Warning: no etag received for %s", key)
 	}
 
-	// Get properties again to get current metadata
+	if checkpoint != nil {
+		if newETag, err := setTransferState(ctx, blobItemClient, StateTransferred); err != nil {
+			log.Printf("Warning: failed to record transfer state for %s: %v", key, err)
+		} else {
+			blobETag = newETag
+		}
+	}
+
+	destination := key
+	if namer, ok := volume.(destinationNamer); ok {
+		destination = namer.Destination(key)
+	}
+
+	return destinationResult{
+		Destination:    destination,
+		ETag:           etag,
+		ChecksumSHA256: sha256Checksum,
+	}, blobETag, nil
+}
+
+// setTransferState records state in the source blob's "transferstate"
+// metadata field, preserving every other field already set, and returns the
+// blob's new ETag so a caller chaining further conditional operations (e.g.
+// transferToVolume's IfMatch-guarded download) knows the value this write
+// left it at rather than the now-stale one it read the blob at. Failures
+// are the caller's to decide on; a state-tracking write that fails
+// shouldn't fail the transfer itself, since ReconcileStaleUploads falling
+// back to re-transferring from scratch is always safe, just slower.
+func setTransferState(ctx context.Context, blobItemClient *azblob.BlobClient, state string) (azcore.ETag, error) {
 	props, err := blobItemClient.GetProperties(ctx, nil)
 	if err != nil {
-		return fmt.Errorf("failed to get blob properties: %w", err)
+		return "", fmt.Errorf("failed to read metadata before recording transfer state: %w", err)
+	}
+	metadata := map[string]*string{}
+	for k, v := range props.Metadata {
+		metadata[k] = v
+	}
+	s := state
+	metadata["transferstate"] = &s
+	resp, err := blobItemClient.SetMetadata(ctx, metadata, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to set transferstate %q: %w", state, err)
+	}
+	if resp.ETag == nil {
+		return "", nil
 	}
+	return *resp.ETag, nil
+}
 
-	// Update blob metadata to indicate successful transfer
-	updatedMetadata := map[string]*string{}
+// setTransferStatus records status in the source blob's "transferstatus"
+// metadata field, preserving every other field already set, and returns the
+// blob's new ETag for the same reason setTransferState above does. It's the
+// "transferstatus" counterpart to setTransferState: transferstate tracks
+// the resumable-upload state machine, while transferstatus is the small set
+// of terminal outcomes (currently just "transferred" and
+// "verification_failed") other code already looks at to decide whether a
+// blob is done.
+func setTransferStatus(ctx context.Context, blobItemClient *azblob.BlobClient, status string) (azcore.ETag, error) {
+	props, err := blobItemClient.GetProperties(ctx, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to read metadata before recording transfer status: %w", err)
+	}
+	metadata := map[string]*string{}
 	for k, v := range props.Metadata {
-		updatedMetadata[k] = v
+		metadata[k] = v
 	}
-	
-	transferredStatus := "transferred"
-	timestamp := getCurrentTimestamp()
-	s3Dest := fmt.Sprintf("%s/%s", s3Client.BucketName(), s3Key)
-	
-	updatedMetadata["transferstatus"] = &transferredStatus
-	updatedMetadata["transfertimestamp"] = &timestamp
-	updatedMetadata["s3destination"] = &s3Dest
-	
-	// If we have a valid ETag (MD5), store it in the metadata
-	if etag != "" {
-		updatedMetadata["s3etag"] = &etag
+	s := status
+	metadata["transferstatus"] = &s
+	resp, err := blobItemClient.SetMetadata(ctx, metadata, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to set transferstatus %q: %w", status, err)
+	}
+	if resp.ETag == nil {
+		return "", nil
 	}
+	return *resp.ETag, nil
+}
 
-	// Set the metadata
-	_, err = blobItemClient.SetMetadata(ctx, updatedMetadata, nil)
+// metaString looks up key in metadata case-insensitively, matching the
+// lookup style already used for vesselid/validationstatus above.
+func metaString(metadata map[string]*string, key string) string {
+	for k, v := range metadata {
+		if strings.EqualFold(k, key) && v != nil {
+			return *v
+		}
+	}
+	return ""
+}
+
+// blobCheckpoint implements aws.UploadCheckpoint by persisting a multipart
+// upload's progress in the source blob's own metadata ("uploadid" and
+// "partsjson"), so a resumed transfer needs no storage beyond the Azure
+// container it already reads the blob from. PartCompleted round-trips to
+// Azure on every part rather than batching, since the entire point is
+// surviving a process restart partway through a multi-gigabyte upload over
+// an unreliable satellite link - the per-part write is cheap next to the
+// part upload itself.
+type blobCheckpoint struct {
+	ctx        context.Context
+	blobClient *azblob.BlobClient
+}
+
+func newBlobCheckpoint(ctx context.Context, blobClient *azblob.BlobClient) *blobCheckpoint {
+	return &blobCheckpoint{ctx: ctx, blobClient: blobClient}
+}
+
+// Resume reads the upload id and completed parts persisted by a prior,
+// interrupted attempt, or ("", nil) if there is none. Parts can complete out
+// of order, so only the gap-free run starting at part 1 is returned, in
+// part-number order; callers resume at byte len(parts)*PartSize.
+func (c *blobCheckpoint) Resume() (string, []aws.CompletedUploadPart) {
+	uploadID, parts := c.load()
+	byNumber := make(map[int32]aws.CompletedUploadPart, len(parts))
+	for _, p := range parts {
+		byNumber[p.PartNumber] = p
+	}
+	var run []aws.CompletedUploadPart
+	for n := int32(1); ; n++ {
+		p, ok := byNumber[n]
+		if !ok {
+			break
+		}
+		run = append(run, p)
+	}
+	return uploadID, run
+}
+
+// load reads the upload id and every part persisted against it.
+func (c *blobCheckpoint) load() (string, []aws.CompletedUploadPart) {
+	props, err := c.blobClient.GetProperties(c.ctx, nil)
 	if err != nil {
-		return fmt.Errorf("failed to update blob metadata: %w", err)
+		log.Printf("Failed to read upload checkpoint metadata: %v", err)
+		return "", nil
+	}
+
+	uploadID := metaString(props.Metadata, "uploadid")
+	if uploadID == "" {
+		return "", nil
+	}
+
+	partsJSON := metaString(props.Metadata, "partsjson")
+	if partsJSON == "" {
+		return uploadID, nil
+	}
+
+	var parts []aws.CompletedUploadPart
+	if err := json.Unmarshal([]byte(partsJSON), &parts); err != nil {
+		log.Printf("Failed to parse checkpointed parts for upload %s: %v", uploadID, err)
+		return "", nil
+	}
+	return uploadID, parts
+}
+
+// PartCompleted appends part to the persisted part list and writes it back
+// along with uploadID, so a restart after this point skips part once every
+// lower-numbered part is recorded too.
+func (c *blobCheckpoint) PartCompleted(uploadID string, part aws.CompletedUploadPart) error {
+	priorUploadID, parts := c.load()
+	if priorUploadID != uploadID {
+		// A fresh upload (no prior checkpoint, or the id changed, e.g. a
+		// stale upload was aborted and a new one started): this part is the
+		// first one recorded against uploadID.
+		parts = nil
+	}
+	parts = append(parts, part)
+
+	partsJSON, err := json.Marshal(parts)
+	if err != nil {
+		return fmt.Errorf("failed to marshal checkpoint parts: %w", err)
+	}
+
+	props, err := c.blobClient.GetProperties(c.ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to read metadata before checkpointing part %d: %w", part.PartNumber, err)
+	}
+	metadata := map[string]*string{}
+	for k, v := range props.Metadata {
+		metadata[k] = v
+	}
+
+	state := StateUploading
+	id := uploadID
+	partsStr := string(partsJSON)
+	metadata["transferstate"] = &state
+	metadata["uploadid"] = &id
+	metadata["partsjson"] = &partsStr
+
+	if _, err := c.blobClient.SetMetadata(c.ctx, metadata, nil); err != nil {
+		return fmt.Errorf("failed to persist checkpoint for part %d: %w", part.PartNumber, err)
+	}
+	return nil
+}
+
+// ReconcileStaleUploads is run at startup (and on whatever interval the
+// caller chooses) to recover from a process restart or crash that left a
+// multipart upload dangling: it lists every Azure blob whose transferstate
+// is still StateUploading, finds the matching in-progress upload against
+// each destination that supports staleUploadLister, and aborts any upload
+// older than staleAfter. A blob whose upload isn't stale yet is left alone;
+// the next ordinary transfer attempt (via TransferValidatedBlob) resumes it
+// from its checkpoint rather than this sweep touching it.
+func ReconcileStaleUploads(
+	ctx context.Context,
+	blobClient *azblob.Client,
+	volumes []storage.Volume,
+	containerName string,
+	staleAfter time.Duration,
+) error {
+	listers := make([]staleUploadLister, 0, len(volumes))
+	for _, v := range volumes {
+		if lister, ok := v.(staleUploadLister); ok {
+			listers = append(listers, lister)
+		}
+	}
+	if len(listers) == 0 {
+		return nil
+	}
+
+	inProgress := make(map[string]aws.MultipartUploadInfo)
+	for _, lister := range listers {
+		uploads, err := lister.ListMultipartUploads(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to list in-progress multipart uploads: %w", err)
+		}
+		for _, u := range uploads {
+			inProgress[u.Key] = u
+		}
 	}
 
+	containerClient := blobClient.ServiceClient().NewContainerClient(containerName)
+	pager := containerClient.NewListBlobsFlatPager(nil)
+
+	reconciled := 0
+	for pager.More() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to list blobs: %w", err)
+		}
+
+		for _, blob := range page.Segment.BlobItems {
+			blobItemClient := containerClient.NewBlobClient(*blob.Name)
+			props, err := blobItemClient.GetProperties(ctx, nil)
+			if err != nil {
+				log.Printf("Failed to get properties for blob %s during upload reconciliation: %v", *blob.Name, err)
+				continue
+			}
+
+			if metaString(props.Metadata, "transferstate") != StateUploading {
+				continue
+			}
+
+			uploadID := metaString(props.Metadata, "uploadid")
+			if uploadID == "" {
+				continue
+			}
+
+			vesselID := metaString(props.Metadata, "vesselid")
+			fileName := filepath.Base(*blob.Name)
+			key := storage.BuildObjectKey(vesselID, fileName)
+
+			info, found := inProgress[key]
+			if !found {
+				// S3 no longer knows about this upload id (it was already
+				// completed or aborted some other way); clear the stale
+				// bookkeeping so the next transfer attempt starts fresh
+				// instead of resuming an upload that no longer exists.
+				log.Printf("Upload %s for blob %s no longer exists in S3, clearing checkpoint", uploadID, *blob.Name)
+				if err := clearUploadCheckpoint(ctx, blobItemClient, props.Metadata); err != nil {
+					log.Printf("Failed to clear stale checkpoint for blob %s: %v", *blob.Name, err)
+				}
+				reconciled++
+				continue
+			}
+
+			if time.Since(info.Initiated) <= staleAfter {
+				continue
+			}
+
+			log.Printf("Aborting stale multipart upload %s for blob %s (initiated %s ago)", uploadID, *blob.Name, time.Since(info.Initiated))
+			for _, lister := range listers {
+				if err := lister.AbortMultipartUpload(ctx, key, uploadID); err != nil {
+					log.Printf("Failed to abort stale upload %s for blob %s: %v", uploadID, *blob.Name, err)
+					continue
+				}
+			}
+			if err := clearUploadCheckpoint(ctx, blobItemClient, props.Metadata); err != nil {
+				log.Printf("Failed to clear checkpoint after aborting upload for blob %s: %v", *blob.Name, err)
+			}
+			reconciled++
+		}
+	}
+
+	if reconciled > 0 {
+		log.Printf("Upload reconciliation sweep cleared %d stale transfer(s)", reconciled)
+	}
 	return nil
 }
 
+// clearUploadCheckpoint resets a blob back to StatePending, dropping its
+// uploadid/partsjson so the next transfer attempt starts a new upload
+// rather than trying to resume one that's been aborted or no longer exists.
+func clearUploadCheckpoint(ctx context.Context, blobItemClient *azblob.BlobClient, currentMetadata map[string]*string) error {
+	metadata := map[string]*string{}
+	for k, v := range currentMetadata {
+		metadata[k] = v
+	}
+	state := StatePending
+	metadata["transferstate"] = &state
+	delete(metadata, "uploadid")
+	delete(metadata, "partsjson")
+	_, err := blobItemClient.SetMetadata(ctx, metadata, nil)
+	return err
+}
+
 // getBlobInfo retrieves information about a blob
 func getBlobInfo(ctx context.Context, client *azblob.Client, containerName, blobName string) (*BlobInfo, error) {
 	containerClient := client.ServiceClient().NewContainerClient(containerName)
@@ -143,23 +806,29 @@ func getBlobInfo(ctx context.Context, client *azblob.Client, containerName, blob
 		return nil, fmt.Errorf("failed to get blob properties: %w", err)
 	}
 
-	// Convert metadata from map[string]*string to map[string]string
-	metadata := make(map[string]string)
-	for k, v := range props.Metadata {
-		if v != nil {
-			metadata[k] = *v
-		}
-	}
+	// Recover each field's original, human-readable name from its
+	// Azure-safe canonicalized form - e.g. "vessel_2Did" back to
+	// "vessel-id" - instead of handing callers the raw identifier-safe keys
+	// Azure actually stored.
+	mapper := metadata.MetadataMapper{}
+	meta := mapper.FromAzure(props.Metadata)
 
 	// Extract metadata
 	info := &BlobInfo{
 		ContainerName: containerName,
 		BlobName:      blobName,
-		Metadata:      metadata,
+		Metadata:      meta,
+		RawMetadata:   props.Metadata,
+	}
+	if props.ETag != nil {
+		info.ETag = *props.ETag
+	}
+	if props.LastModified != nil {
+		info.LastModified = *props.LastModified
 	}
 
 	// Extract additional metadata fields into struct fields - case insensitive lookups
-	for k, v := range metadata {
+	for k, v := range meta {
 		if strings.EqualFold(k, "checksum") {
 			info.Checksum = v
 		} else if strings.EqualFold(k, "vesselid") {
@@ -175,4 +844,4 @@ func getBlobInfo(ctx context.Context, client *azblob.Client, containerName, blob
 // getCurrentTimestamp returns the current timestamp in ISO 8601 format
 func getCurrentTimestamp() string {
 	return time.Now().UTC().Format(time.RFC3339)
-}
\ No newline at end of file
+}
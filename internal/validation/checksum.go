@@ -1,22 +1,28 @@
 package validation
 
 import (
-	"bytes"
 	"context"
 	"crypto/md5"
 	"crypto/sha256"
 	"encoding/hex"
 	"fmt"
+	"hash"
 	"io"
 	"log"
 	"strings"
 	"time"
 
 	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob"
+	"github.com/CAST-Intelligence/elysium-usv/internal/metrics"
+	"github.com/CAST-Intelligence/elysium-usv/internal/storage"
 )
 
-// VerifyChecksum validates the checksum of a blob against the expected value
-func VerifyChecksum(ctx context.Context, client *azblob.Client, containerName, blobName string, expectedChecksum string, algorithm string) (bool, error) {
+// VerifyChecksum validates the checksum of a blob against the expected value.
+// It delegates to VerifyChecksumStreaming so the blob body is hashed as it is
+// downloaded rather than buffered in memory first. reg may be nil.
+func VerifyChecksum(ctx context.Context, client *azblob.Client, containerName, blobName string, expectedChecksum string, algorithm string, reg *metrics.Registry) (bool, error) {
+	start := time.Now()
+
 	// Get a container client and then a blob client
 	containerClient := client.ServiceClient().NewContainerClient(containerName)
 	blobClient := containerClient.NewBlobClient(blobName)
@@ -24,36 +30,42 @@ func VerifyChecksum(ctx context.Context, client *azblob.Client, containerName, b
 	// Download the blob
 	response, err := blobClient.DownloadStream(ctx, nil)
 	if err != nil {
+		reg.ObserveBlobOp("checksum_verify", "", containerName, 0, time.Since(start), err)
 		return false, fmt.Errorf("failed to download blob: %w", err)
 	}
+	defer response.Body.Close()
 
-	// Read the blob data
-	body := response.Body
-	defer body.Close()
-
-	// Buffer to store the blob data
-	var buffer bytes.Buffer
-	_, err = io.Copy(&buffer, body)
-	if err != nil {
-		return false, fmt.Errorf("failed to read blob data: %w", err)
+	var contentLength int64
+	if response.ContentLength != nil {
+		contentLength = *response.ContentLength
 	}
 
-	// Calculate the checksum based on algorithm
-	var calculatedChecksum string
+	isValid, err := VerifyChecksumStreaming(response.Body, expectedChecksum, algorithm)
+	reg.ObserveBlobOp("checksum_verify", "", containerName, contentLength, time.Since(start), err)
+	return isValid, err
+}
+
+// VerifyChecksumStreaming validates a checksum by hashing body as it is read,
+// rather than buffering it in memory first. This is the streaming
+// counterpart to VerifyChecksum for callers that already hold an open
+// io.Reader (e.g. a blob download already in progress).
+func VerifyChecksumStreaming(body io.Reader, expectedChecksum string, algorithm string) (bool, error) {
+	var hasher hash.Hash
 	switch strings.ToUpper(algorithm) {
 	case "MD5":
-		calculatedChecksum, err = calculateMD5(buffer.Bytes())
+		hasher = md5.New()
 	case "SHA256", "":
 		// Default to SHA256 if not specified
-		calculatedChecksum, err = calculateSHA256(buffer.Bytes())
+		hasher = sha256.New()
 	default:
 		return false, fmt.Errorf("unsupported checksum algorithm: %s", algorithm)
 	}
 
-	if err != nil {
-		return false, fmt.Errorf("failed to calculate checksum: %w", err)
+	if _, err := io.Copy(hasher, body); err != nil {
+		return false, fmt.Errorf("failed to read blob data: %w", err)
 	}
 
+	calculatedChecksum := hex.EncodeToString(hasher.Sum(nil))
 	log.Printf("Checksum comparison: calculated=%s, expected=%s, algorithm=%s", calculatedChecksum, expectedChecksum, algorithm)
 
 	// Compare the checksums
@@ -61,50 +73,19 @@ func VerifyChecksum(ctx context.Context, client *azblob.Client, containerName, b
 	return isValid, nil
 }
 
-// calculateSHA256 calculates the SHA256 hash of the data
-func calculateSHA256(data []byte) (string, error) {
-	hasher := sha256.New()
-	_, err := hasher.Write(data)
-	if err != nil {
-		return "", err
-	}
-	return hex.EncodeToString(hasher.Sum(nil)), nil
-}
-
-// calculateMD5 calculates the MD5 hash of the data
-func calculateMD5(data []byte) (string, error) {
-	hasher := md5.New()
-	_, err := hasher.Write(data)
-	if err != nil {
-		return "", err
-	}
-	return hex.EncodeToString(hasher.Sum(nil)), nil
-}
-
-// ValidateBlob performs validation on a blob and updates its metadata
-func ValidateBlob(ctx context.Context, client *azblob.Client, containerName, blobName string) (bool, error) {
-	// Get a container client and then a blob client
-	containerClient := client.ServiceClient().NewContainerClient(containerName)
-	blobClient := containerClient.NewBlobClient(blobName)
-
-	// Get the blob properties to access metadata
-	props, err := blobClient.GetProperties(ctx, nil)
+// ValidateBlob performs validation on a blob and updates its metadata. It
+// runs against any storage.ObjectStore backend (not just Azure Blob
+// Storage), so ValidationWorker can be pointed at a different driver via
+// config.Config.StorageDriver.
+func ValidateBlob(ctx context.Context, store storage.ObjectStore, blobName string) (bool, error) {
+	exists, _, metadata, err := store.Head(ctx, blobName)
 	if err != nil {
 		return false, fmt.Errorf("failed to get blob properties: %w", err)
 	}
-
-	// Extract metadata - need to handle nil strings
-	metadata := make(map[string]string)
-	log.Printf("Blob %s has %d metadata entries", blobName, len(props.Metadata))
-
-	for k, v := range props.Metadata {
-		if v != nil {
-			metadata[k] = *v
-			log.Printf("Blob %s has metadata: %s=%s", blobName, k, *v)
-		} else {
-			log.Printf("Blob %s has nil metadata value for key: %s", blobName, k)
-		}
+	if !exists {
+		return false, fmt.Errorf("blob %s not found", blobName)
 	}
+	log.Printf("Blob %s has %d metadata entries", blobName, len(metadata))
 
 	// Check for checksum in metadata - try both "checksum" and case variations
 	expectedChecksum, ok := metadata["checksum"]
@@ -131,29 +112,28 @@ func ValidateBlob(ctx context.Context, client *azblob.Client, containerName, blo
 
 	log.Printf("Using %s algorithm for checksum validation of blob %s", algorithm, blobName)
 
-	// Verify the checksum with the appropriate algorithm
-	isValid, err := VerifyChecksum(ctx, client, containerName, blobName, expectedChecksum, algorithm)
+	// Stream the blob straight into the hasher rather than buffering it in
+	// memory first.
+	body, err := store.Get(ctx, blobName)
+	if err != nil {
+		return false, fmt.Errorf("failed to download blob: %w", err)
+	}
+	defer body.Close()
+
+	isValid, err := VerifyChecksumStreaming(body, expectedChecksum, algorithm)
 	if err != nil {
 		return false, err
 	}
 
-	// Update the metadata map for Azure SDK
-	updatedMetadata := map[string]*string{}
-	for k, v := range props.Metadata {
+	// Add validation status fields to the existing metadata and write it back
+	updatedMetadata := make(map[string]string, len(metadata)+2)
+	for k, v := range metadata {
 		updatedMetadata[k] = v
 	}
+	updatedMetadata["validationstatus"] = getValidationStatus(isValid)
+	updatedMetadata["validationtimestamp"] = getCurrentTimestamp()
 
-	// Add validation status fields
-	validStatus := getValidationStatus(isValid)
-	timestamp := getCurrentTimestamp()
-	validStatusPtr := &validStatus
-	timestampPtr := &timestamp
-	updatedMetadata["validationstatus"] = validStatusPtr
-	updatedMetadata["validationtimestamp"] = timestampPtr
-
-	// Set the updated metadata
-	_, err = blobClient.SetMetadata(ctx, updatedMetadata, nil)
-	if err != nil {
+	if err := store.SetMetadata(ctx, blobName, updatedMetadata); err != nil {
 		return false, fmt.Errorf("failed to update blob metadata: %w", err)
 	}
 
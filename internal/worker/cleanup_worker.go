@@ -8,55 +8,92 @@ import (
 
 	"github.com/CAST-Intelligence/elysium-usv/internal/audit"
 	"github.com/CAST-Intelligence/elysium-usv/internal/config"
+	"github.com/CAST-Intelligence/elysium-usv/internal/metrics"
 	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob"
 	"github.com/Azure/azure-sdk-for-go/sdk/storage/azqueue"
 )
 
-// CleanupWorker processes blobs for cleanup after transfer
+// CleanupWorker processes blobs for cleanup after transfer. Cleanup is a
+// two-phase trash model modeled on Arvados' BlobTrashLifetime: a blob is
+// first marked trashed (trashstatus=trashed, trashat=now+trashLifetime) and
+// only physically deleted once trashat has passed, giving operators a
+// window to RestoreBlob a blob whose S3 copy is later found bad.
 type CleanupWorker struct {
-	worker        *Worker
-	blobClient    *azblob.Client
-	queueClient   *azqueue.ServiceClient
-	containerName string
-	queueName     string
-	retentionDays int
+	worker           *Worker
+	trashEmptier     *Worker
+	blobClient       *azblob.Client
+	queueClient      *azqueue.ServiceClient
+	containerName    string
+	queueName        string
+	retentionDays    int
+	trashLifetime    time.Duration
+	deadLetterSink   DeadLetterSink
+	maxDeliveryCount int32
+	baseBackoff      time.Duration
+	maxBackoff       time.Duration
+	failureTracker   *messageFailureTracker
+	metrics          *metrics.Registry
 }
 
-// NewCleanupWorker creates a new worker for blob cleanup
+// NewCleanupWorker creates a new worker for blob cleanup. reg may be nil.
 func NewCleanupWorker(
 	cfg *config.Config,
 	blobClient *azblob.Client,
 	queueClient *azqueue.ServiceClient,
+	reg *metrics.Registry,
 ) *CleanupWorker {
 	cw := &CleanupWorker{
-		blobClient:    blobClient,
-		queueClient:   queueClient,
-		containerName: cfg.BlobContainerName,
-		queueName:     cfg.CleanupQueueName,
-		retentionDays: cfg.RetentionDays,
+		blobClient:       blobClient,
+		queueClient:      queueClient,
+		containerName:    cfg.BlobContainerName,
+		queueName:        cfg.CleanupQueueName,
+		retentionDays:    cfg.RetentionDays,
+		trashLifetime:    cfg.BlobTrashLifetime,
+		deadLetterSink:   NewAzureQueueDeadLetterSink(queueClient, cfg.DeadLetterQueueName),
+		maxDeliveryCount: cfg.MaxDeliveryCount,
+		baseBackoff:      cfg.DeadLetterBaseBackoff,
+		maxBackoff:       cfg.DeadLetterMaxBackoff,
+		failureTracker:   newMessageFailureTracker(),
+		metrics:          reg,
 	}
 
-	// Create the underlying worker
+	// Create the underlying worker that marks transferred blobs trashed,
+	// driven by the cleanup queue (falling back to the retention-day scan)
 	worker := NewWorker(
 		"cleanup",
 		cw.processCleanupQueue,
 		30*time.Second, // Poll every 30 seconds
 		cfg.ProcessingBatchSize,
 		cfg.OperationRetryCount,
+		reg,
 	)
-
 	cw.worker = worker
+
+	// Create the second worker that empties the trash: a separate interval
+	// and batch size since it only needs to run as often as trashLifetime
+	// actually expires blobs, not every cleanup-queue poll
+	cw.trashEmptier = NewWorker(
+		"trash-emptier",
+		cw.processEmptyTrash,
+		cfg.BlobTrashCheckInterval,
+		cfg.EmptyTrashBatchSize,
+		cfg.OperationRetryCount,
+		reg,
+	)
+
 	return cw
 }
 
 // Start starts the cleanup worker
 func (cw *CleanupWorker) Start() {
 	cw.worker.Start()
+	cw.trashEmptier.Start()
 }
 
 // Stop stops the cleanup worker
 func (cw *CleanupWorker) Stop() {
 	cw.worker.Stop()
+	cw.trashEmptier.Stop()
 }
 
 // Status returns the worker status
@@ -69,29 +106,61 @@ func (cw *CleanupWorker) LastRun() time.Time {
 	return cw.worker.LastRun()
 }
 
-// processCleanupQueue processes messages from the cleanup queue
+// Pause, Resume, and IsPaused apply to both of CleanupWorker's loops (mark
+// trash and empty trash), so an operator pausing "the cleanup worker"
+// through the admin API stops both phases together.
+func (cw *CleanupWorker) Pause() {
+	cw.worker.Pause()
+	cw.trashEmptier.Pause()
+}
+
+func (cw *CleanupWorker) Resume() {
+	cw.worker.Resume()
+	cw.trashEmptier.Resume()
+}
+
+func (cw *CleanupWorker) IsPaused() bool { return cw.worker.IsPaused() }
+
+// Interval, BatchSize, and RetryCount report the mark-trash loop's
+// tunables; the empty-trash loop's own interval/batch size are config-only
+// and not exposed through the admin API.
+func (cw *CleanupWorker) Interval() time.Duration { return cw.worker.Interval() }
+func (cw *CleanupWorker) BatchSize() int          { return cw.worker.BatchSize() }
+func (cw *CleanupWorker) RetryCount() int         { return cw.worker.RetryCount() }
+
+// Reconfigure applies new runtime tunables to the mark-trash loop.
+// visibilityTimeout is ignored; CleanupWorker has no visibility timeout
+// tunable of its own.
+func (cw *CleanupWorker) Reconfigure(interval time.Duration, batchSize, retryCount int, visibilityTimeout time.Duration) {
+	cw.worker.Reconfigure(interval, batchSize, retryCount)
+}
+
+// processCleanupQueue processes messages from the cleanup queue, trashing
+// the blob named by each message
 func (cw *CleanupWorker) processCleanupQueue(ctx context.Context, batchSize int) error {
 	queueClient := cw.queueClient.NewQueueClient(cw.queueName)
+	observeQueueDepth(ctx, queueClient, cw.queueName, cw.metrics)
 
 	// Create options for dequeuing messages
 	options := &azqueue.DequeueMessagesOptions{
 		NumberOfMessages: &[]int32{int32(batchSize)}[0], // Convert batch size to int32 pointer
 		VisibilityTimeout: &[]int32{30}[0], // 30 seconds visibility timeout
 	}
-	
+
 	// Dequeue messages from the queue
 	resp, err := queueClient.DequeueMessages(ctx, options)
 	if err != nil {
 		return fmt.Errorf("failed to dequeue messages: %w", err)
 	}
 
-	// If no messages, process expired blobs instead
+	// If no messages, fall back to the retention-day mark-trash scan
 	if len(resp.Messages) == 0 {
-		log.Println("No cleanup messages found in queue, checking for expired blobs")
-		return cw.processExpiredBlobs(ctx, batchSize)
+		log.Println("No cleanup messages found in queue, checking for blobs past retention")
+		return cw.processMarkTrash(ctx, batchSize)
 	}
-	
+
 	log.Printf("Received %d messages from cleanup queue", len(resp.Messages))
+	cw.metrics.ObserveQueueDequeue(cw.queueName, cw.containerName, len(resp.Messages))
 
 	// Process each message
 	for _, msg := range resp.Messages {
@@ -101,51 +170,64 @@ func (cw *CleanupWorker) processCleanupQueue(ctx context.Context, batchSize int)
 			log.Printf("Received message with nil MessageText, skipping")
 			continue
 		}
-		
+		if msg.InsertionTime != nil {
+			cw.metrics.ObserveQueueMessageAge(cw.queueName, cw.containerName, time.Since(*msg.InsertionTime))
+		}
+
 		blobName := *msg.MessageText
 		log.Printf("Processing cleanup message for blob: %s", blobName)
 
-		// Cleanup the blob
-		err := cw.cleanupBlob(ctx, blobName)
+		// Mark the blob trashed
+		err := cw.trashBlob(ctx, blobName)
 		if err != nil {
-			log.Printf("Failed to cleanup blob %s: %v", blobName, err)
+			log.Printf("Failed to trash blob %s: %v", blobName, err)
+			if dlqErr := handleDequeueFailure(ctx, queueClient, cw.queueName, msg, blobName, nil, err, cw.maxDeliveryCount, cw.baseBackoff, cw.maxBackoff, cw.failureTracker, cw.deadLetterSink, cw.metrics); dlqErr != nil {
+				log.Printf("Failed to handle cleanup failure for blob %s: %v", blobName, dlqErr)
+			}
 			continue
 		}
 
-		// If cleanup successful, delete the message from the queue
+		// If trashing succeeded, delete the message from the queue
 		// Need to check if MessageID and PopReceipt are nil
 		if msg.MessageID == nil || msg.PopReceipt == nil {
 			log.Printf("Received message with nil MessageID or PopReceipt, skipping")
 			continue
 		}
-		
+
 		_, err = queueClient.DeleteMessage(ctx, *msg.MessageID, *msg.PopReceipt, nil)
 		if err != nil {
 			log.Printf("Failed to delete message for blob %s: %v", blobName, err)
 			continue
 		}
 
-		log.Printf("Blob %s cleaned up successfully", blobName)
+		// The message succeeded, possibly after earlier transient failures -
+		// drop its tracked attempts so messageFailureTracker doesn't hold an
+		// entry for it forever.
+		cw.failureTracker.forget(*msg.MessageID)
+
+		log.Printf("Blob %s trashed successfully", blobName)
 	}
 
 	return nil
 }
 
-// processExpiredBlobs looks for blobs that are beyond retention period and cleans them up
-func (cw *CleanupWorker) processExpiredBlobs(ctx context.Context, batchSize int) error {
+// processMarkTrash looks for blobs that have been transferred and are past
+// the retention period, and marks them trashed. This is the first pass of
+// the two-phase trash model; it never deletes a blob directly.
+func (cw *CleanupWorker) processMarkTrash(ctx context.Context, batchSize int) error {
 	// Create container client
 	containerClient := cw.blobClient.ServiceClient().NewContainerClient(cw.containerName)
 
 	// List blobs
 	pager := containerClient.NewListBlobsFlatPager(nil)
-	
+
 	processed := 0
 	for pager.More() && processed < batchSize {
 		resp, err := pager.NextPage(ctx)
 		if err != nil {
 			return fmt.Errorf("failed to list blobs: %w", err)
 		}
-		
+
 		for _, blob := range resp.Segment.BlobItems {
 			// Check if the blob has been transferred (via metadata)
 			blobClient := containerClient.NewBlobClient(*blob.Name)
@@ -155,13 +237,16 @@ func (cw *CleanupWorker) processExpiredBlobs(ctx context.Context, batchSize int)
 				continue
 			}
 
-			// Skip if not transferred yet
-			var transferStatus string
+			// Skip if not transferred yet, or already trashed
+			var transferStatus, trashStatus string
 			if v := props.Metadata["transferstatus"]; v != nil {
 				transferStatus = *v
 			}
-			
-			if transferStatus != "transferred" {
+			if v := props.Metadata["trashstatus"]; v != nil {
+				trashStatus = *v
+			}
+
+			if transferStatus != "transferred" || trashStatus == "trashed" {
 				continue
 			}
 
@@ -169,17 +254,17 @@ func (cw *CleanupWorker) processExpiredBlobs(ctx context.Context, batchSize int)
 			if blob.Properties.LastModified != nil {
 				lastModTime := *blob.Properties.LastModified
 				retentionPeriod := time.Duration(cw.retentionDays) * 24 * time.Hour
-				
+
 				if time.Since(lastModTime) > retentionPeriod {
-					// Blob is past retention, clean it up
-					if err := cw.cleanupBlob(ctx, *blob.Name); err != nil {
-						log.Printf("Failed to cleanup expired blob %s: %v", *blob.Name, err)
+					// Blob is past retention, mark it trashed
+					if err := cw.trashBlob(ctx, *blob.Name); err != nil {
+						log.Printf("Failed to trash expired blob %s: %v", *blob.Name, err)
 						continue
 					}
-					
-					log.Printf("Expired blob %s cleaned up successfully", *blob.Name)
+
+					log.Printf("Expired blob %s trashed successfully", *blob.Name)
 					processed++
-					
+
 					if processed >= batchSize {
 						break
 					}
@@ -191,46 +276,202 @@ func (cw *CleanupWorker) processExpiredBlobs(ctx context.Context, batchSize int)
 	return nil
 }
 
-// cleanupBlob performs cleanup operations on a blob
-func (cw *CleanupWorker) cleanupBlob(ctx context.Context, blobName string) error {
+// processEmptyTrash looks for blobs already marked trashed whose trashat
+// has passed, and physically deletes them. This is the second pass of the
+// two-phase trash model, driven by its own BlobTrashCheckInterval and
+// EmptyTrashBatchSize independent of the mark-trash scan above.
+func (cw *CleanupWorker) processEmptyTrash(ctx context.Context, batchSize int) error {
+	containerClient := cw.blobClient.ServiceClient().NewContainerClient(cw.containerName)
+
+	pager := containerClient.NewListBlobsFlatPager(nil)
+
+	processed := 0
+	for pager.More() && processed < batchSize {
+		resp, err := pager.NextPage(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to list blobs: %w", err)
+		}
+
+		for _, blob := range resp.Segment.BlobItems {
+			blobClient := containerClient.NewBlobClient(*blob.Name)
+			props, err := blobClient.GetProperties(ctx, nil)
+			if err != nil {
+				log.Printf("Failed to get properties for blob %s: %v", *blob.Name, err)
+				continue
+			}
+
+			var trashStatus, trashAt string
+			if v := props.Metadata["trashstatus"]; v != nil {
+				trashStatus = *v
+			}
+			if v := props.Metadata["trashat"]; v != nil {
+				trashAt = *v
+			}
+
+			if trashStatus != "trashed" || trashAt == "" {
+				continue
+			}
+
+			trashAtTime, err := time.Parse(time.RFC3339, trashAt)
+			if err != nil {
+				log.Printf("Blob %s has unparseable trashat %q, skipping: %v", *blob.Name, trashAt, err)
+				continue
+			}
+
+			if time.Now().UTC().Before(trashAtTime) {
+				continue
+			}
+
+			if err := cw.emptyTrash(ctx, *blob.Name); err != nil {
+				log.Printf("Failed to delete trashed blob %s: %v", *blob.Name, err)
+				continue
+			}
+
+			log.Printf("Trashed blob %s deleted successfully", *blob.Name)
+			processed++
+
+			if processed >= batchSize {
+				break
+			}
+		}
+	}
+
+	return nil
+}
+
+// trashBlob marks a transferred blob trashed rather than deleting it
+// immediately: trashstatus is set to "trashed" and trashat to now plus the
+// configured trash lifetime, giving RestoreBlob a window to recover the
+// blob if its S3 copy is later found bad.
+func (cw *CleanupWorker) trashBlob(ctx context.Context, blobName string) error {
 	// Create blob client
 	containerClient := cw.blobClient.ServiceClient().NewContainerClient(cw.containerName)
 	blobClient := containerClient.NewBlobClient(blobName)
-	
+
 	// Get properties to verify it has been transferred
 	props, err := blobClient.GetProperties(ctx, nil)
 	if err != nil {
 		return fmt.Errorf("failed to get blob properties: %w", err)
 	}
-	
-	// Ensure the blob has been transferred before deletion
+
+	// Ensure the blob has been transferred before trashing it
 	var transferStatus, s3Destination string
 	if v := props.Metadata["transferstatus"]; v != nil {
 		transferStatus = *v
 	}
-	
+
 	if transferStatus != "transferred" {
 		return fmt.Errorf("blob %s has not been transferred yet", blobName)
 	}
-	
+
+	if v := props.Metadata["trashstatus"]; v != nil && *v == "trashed" {
+		// Already trashed, nothing to do
+		return nil
+	}
+
 	// Get the S3 destination for the audit log
 	if v := props.Metadata["s3destination"]; v != nil {
 		s3Destination = *v
 	} else {
 		s3Destination = "unknown"
 	}
-	
+
+	// Generate an audit certificate recording the trash event, distinct
+	// from the "deletion" certificate emptyTrash generates later
+	if err := audit.GenerateAuditCertificateForOperation(ctx, blobName, s3Destination, "trash"); err != nil {
+		return fmt.Errorf("failed to generate audit certificate: %w", err)
+	}
+
+	updatedMetadata := map[string]*string{}
+	for k, v := range props.Metadata {
+		updatedMetadata[k] = v
+	}
+	trashStatus := "trashed"
+	trashAt := time.Now().UTC().Add(cw.trashLifetime).Format(time.RFC3339)
+	updatedMetadata["trashstatus"] = &trashStatus
+	updatedMetadata["trashat"] = &trashAt
+
+	if _, err := blobClient.SetMetadata(ctx, updatedMetadata, nil); err != nil {
+		return fmt.Errorf("failed to mark blob trashed: %w", err)
+	}
+
+	return nil
+}
+
+// emptyTrash physically deletes a blob already marked trashed, once its
+// trashat has passed.
+func (cw *CleanupWorker) emptyTrash(ctx context.Context, blobName string) error {
+	// Create blob client
+	containerClient := cw.blobClient.ServiceClient().NewContainerClient(cw.containerName)
+	blobClient := containerClient.NewBlobClient(blobName)
+
+	props, err := blobClient.GetProperties(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to get blob properties: %w", err)
+	}
+
+	var s3Destination string
+	if v := props.Metadata["s3destination"]; v != nil {
+		s3Destination = *v
+	} else {
+		s3Destination = "unknown"
+	}
+
+	// Get the server-verified SHA256 checksum recorded by the transfer
+	// worker, if any, so the audit certificate attests to byte-for-byte
+	// integrity at the destination rather than just the fact of deletion
+	var s3ChecksumSHA256 string
+	if v := props.Metadata["s3checksumsha256"]; v != nil {
+		s3ChecksumSHA256 = *v
+	}
+
 	// Generate an audit certificate before deletion
-	if err := audit.GenerateAuditCertificate(ctx, blobName, s3Destination); err != nil {
+	if err := audit.GenerateAuditCertificateWithChecksum(ctx, blobName, s3Destination, s3ChecksumSHA256); err != nil {
 		return fmt.Errorf("failed to generate audit certificate: %w", err)
 	}
-	
+
 	// Delete the blob
 	_, err = blobClient.Delete(ctx, nil)
 	if err != nil {
 		return fmt.Errorf("failed to delete blob: %w", err)
 	}
-	
+
+	return nil
+}
+
+// RestoreBlob clears a blob's trashed status, if the object is still
+// present, undoing a pending trashBlob so processEmptyTrash will no longer
+// pick it up for deletion.
+func (cw *CleanupWorker) RestoreBlob(ctx context.Context, blobName string) error {
+	containerClient := cw.blobClient.ServiceClient().NewContainerClient(cw.containerName)
+	blobClient := containerClient.NewBlobClient(blobName)
+
+	props, err := blobClient.GetProperties(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to get blob properties: %w", err)
+	}
+
+	var trashStatus string
+	if v := props.Metadata["trashstatus"]; v != nil {
+		trashStatus = *v
+	}
+	if trashStatus != "trashed" {
+		return fmt.Errorf("blob %s is not trashed", blobName)
+	}
+
+	updatedMetadata := map[string]*string{}
+	for k, v := range props.Metadata {
+		if k == "trashstatus" || k == "trashat" {
+			continue
+		}
+		updatedMetadata[k] = v
+	}
+
+	if _, err := blobClient.SetMetadata(ctx, updatedMetadata, nil); err != nil {
+		return fmt.Errorf("failed to restore blob %s: %w", blobName, err)
+	}
+
+	log.Printf("Blob %s restored from trash", blobName)
 	return nil
 }
 
@@ -0,0 +1,321 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"math/rand"
+	"strings"
+	"time"
+
+	"github.com/CAST-Intelligence/elysium-usv/internal/metrics"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azqueue"
+)
+
+// DeadLetterRecord captures everything an operator needs to diagnose and
+// replay a message that failed processing too many times: the original
+// queue and payload, the error that finally gave up on it, a timestamped
+// history of every attempt, and a snapshot of the blob's metadata at the
+// time it was dead-lettered.
+type DeadLetterRecord struct {
+	QueueName         string            `json:"queueName"`
+	BlobName          string            `json:"blobName"`
+	MessageText       string            `json:"messageText"`
+	LastError         string            `json:"lastError"`
+	Attempts          int               `json:"attempts"`
+	AttemptTimestamps []time.Time       `json:"attemptTimestamps"`
+	BlobMetadata      map[string]string `json:"blobMetadata,omitempty"`
+	DeadLetteredAt    time.Time         `json:"deadLetteredAt"`
+}
+
+// DeadLetterSink is where poison messages go once they exceed their
+// MaxDeliveryCount, and where an operator can list or replay them from.
+// Implementations must be safe for concurrent use by multiple workers.
+type DeadLetterSink interface {
+	Send(ctx context.Context, record DeadLetterRecord) error
+	List(ctx context.Context, filter DeadLetterFilter) ([]DeadLetterRecord, error)
+	Requeue(ctx context.Context, filter DeadLetterFilter, targetQueue string) (int, error)
+}
+
+// DeadLetterFilter selects which dead-lettered messages an operation
+// applies to. An empty filter matches everything.
+type DeadLetterFilter struct {
+	QueueName string
+	BlobName  string
+}
+
+func (f DeadLetterFilter) matches(r DeadLetterRecord) bool {
+	if f.QueueName != "" && f.QueueName != r.QueueName {
+		return false
+	}
+	if f.BlobName != "" && f.BlobName != r.BlobName {
+		return false
+	}
+	return true
+}
+
+// AzureQueueDeadLetterSink stores dead-lettered messages as JSON-encoded
+// DeadLetterRecord payloads in a single Azure Storage Queue shared across
+// all workers, distinguished by DeadLetterRecord.QueueName.
+type AzureQueueDeadLetterSink struct {
+	serviceClient *azqueue.ServiceClient
+	queueName     string
+}
+
+// NewAzureQueueDeadLetterSink creates a sink backed by the queue named
+// cfg.DeadLetterQueueName on the given service client.
+func NewAzureQueueDeadLetterSink(serviceClient *azqueue.ServiceClient, queueName string) *AzureQueueDeadLetterSink {
+	return &AzureQueueDeadLetterSink{
+		serviceClient: serviceClient,
+		queueName:     queueName,
+	}
+}
+
+// Send appends record to the dead-letter queue.
+func (s *AzureQueueDeadLetterSink) Send(ctx context.Context, record DeadLetterRecord) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("failed to marshal dead-letter record: %w", err)
+	}
+
+	client := s.serviceClient.NewQueueClient(s.queueName)
+	if _, err := client.Create(ctx, nil); err != nil && !isQueueAlreadyExistsError(err) {
+		return fmt.Errorf("failed to create dead-letter queue: %w", err)
+	}
+
+	if _, err := client.EnqueueMessage(ctx, string(data), nil); err != nil {
+		return fmt.Errorf("failed to enqueue dead-letter record: %w", err)
+	}
+
+	return nil
+}
+
+// List peeks at messages currently on the dead-letter queue and returns
+// those matching filter. Peeking leaves the messages in place, so this is
+// safe to call without affecting delivery.
+func (s *AzureQueueDeadLetterSink) List(ctx context.Context, filter DeadLetterFilter) ([]DeadLetterRecord, error) {
+	client := s.serviceClient.NewQueueClient(s.queueName)
+
+	maxMessages := int32(32)
+	resp, err := client.PeekMessages(ctx, &azqueue.PeekMessagesOptions{NumberOfMessages: &maxMessages})
+	if err != nil {
+		return nil, fmt.Errorf("failed to peek dead-letter queue: %w", err)
+	}
+
+	var records []DeadLetterRecord
+	for _, msg := range resp.Messages {
+		if msg.MessageText == nil {
+			continue
+		}
+		var record DeadLetterRecord
+		if err := json.Unmarshal([]byte(*msg.MessageText), &record); err != nil {
+			log.Printf("Skipping unparseable dead-letter message: %v", err)
+			continue
+		}
+		if filter.matches(record) {
+			records = append(records, record)
+		}
+	}
+
+	return records, nil
+}
+
+// Requeue dequeues every dead-lettered message matching filter, deletes it
+// from the dead-letter queue, and re-enqueues its original blob name onto
+// targetQueue for reprocessing. It returns the number of messages requeued.
+func (s *AzureQueueDeadLetterSink) Requeue(ctx context.Context, filter DeadLetterFilter, targetQueue string) (int, error) {
+	dlqClient := s.serviceClient.NewQueueClient(s.queueName)
+	targetClient := s.serviceClient.NewQueueClient(targetQueue)
+
+	maxMessages := int32(32)
+	visibilityTimeout := int32(30)
+	requeued := 0
+
+	for {
+		resp, err := dlqClient.DequeueMessages(ctx, &azqueue.DequeueMessagesOptions{
+			NumberOfMessages:  &maxMessages,
+			VisibilityTimeout: &visibilityTimeout,
+		})
+		if err != nil {
+			return requeued, fmt.Errorf("failed to dequeue from dead-letter queue: %w", err)
+		}
+		if len(resp.Messages) == 0 {
+			return requeued, nil
+		}
+
+		for _, msg := range resp.Messages {
+			if msg.MessageText == nil || msg.MessageID == nil || msg.PopReceipt == nil {
+				continue
+			}
+
+			var record DeadLetterRecord
+			if err := json.Unmarshal([]byte(*msg.MessageText), &record); err != nil {
+				log.Printf("Skipping unparseable dead-letter message: %v", err)
+				continue
+			}
+
+			if !filter.matches(record) {
+				// Not ours to touch this pass; let its visibility timeout
+				// expire so it becomes available again.
+				continue
+			}
+
+			if _, err := targetClient.EnqueueMessage(ctx, record.BlobName, nil); err != nil {
+				log.Printf("Failed to requeue dead-lettered blob %s onto %s: %v", record.BlobName, targetQueue, err)
+				continue
+			}
+
+			if _, err := dlqClient.DeleteMessage(ctx, *msg.MessageID, *msg.PopReceipt, nil); err != nil {
+				log.Printf("Failed to delete replayed dead-letter message for blob %s: %v", record.BlobName, err)
+				continue
+			}
+
+			requeued++
+		}
+	}
+}
+
+// observeQueueDepth records a queue's approximate message count on reg,
+// fetched via azqueue's GetProperties. It's best-effort: a failed
+// GetProperties call just logs and leaves the gauge at its last known
+// value, since depth is a secondary signal and shouldn't block polling.
+func observeQueueDepth(ctx context.Context, queueClient *azqueue.QueueClient, queueName string, reg *metrics.Registry) {
+	props, err := queueClient.GetProperties(ctx, nil)
+	if err != nil {
+		log.Printf("Failed to get properties for queue %s: %v", queueName, err)
+		return
+	}
+	if props.ApproximateMessagesCount != nil {
+		reg.SetQueueDepth(queueName, *props.ApproximateMessagesCount)
+	}
+}
+
+func isQueueAlreadyExistsError(err error) bool {
+	return err != nil && (strings.Contains(err.Error(), "QueueAlreadyExists") || strings.Contains(err.Error(), "already exists"))
+}
+
+// messageFailureTracker remembers attempt timestamps for in-flight
+// messages within this process, keyed by message ID. It is best-effort:
+// the history resets if the worker restarts, since Azure Storage Queues
+// don't expose per-message attempt history beyond DequeueCount.
+type messageFailureTracker struct {
+	attempts map[string][]time.Time
+}
+
+func newMessageFailureTracker() *messageFailureTracker {
+	return &messageFailureTracker{attempts: make(map[string][]time.Time)}
+}
+
+func (t *messageFailureTracker) record(messageID string, at time.Time) []time.Time {
+	t.attempts[messageID] = append(t.attempts[messageID], at)
+	return t.attempts[messageID]
+}
+
+func (t *messageFailureTracker) forget(messageID string) {
+	delete(t.attempts, messageID)
+}
+
+// handleDequeueFailure decides what to do with a message that failed
+// processing: if it has been delivered at least maxDeliveryCount times, it
+// is moved to deadLetterSink along with failure metadata; otherwise its
+// visibility timeout is extended with exponential backoff plus jitter so
+// it is retried later rather than immediately, avoiding a tight
+// dequeue-fail-requeue loop against a transiently unavailable dependency.
+func handleDequeueFailure(
+	ctx context.Context,
+	queueClient *azqueue.QueueClient,
+	queueName string,
+	msg azqueue.DequeuedMessage,
+	blobName string,
+	blobMetadata map[string]string,
+	procErr error,
+	maxDeliveryCount int32,
+	baseBackoff, maxBackoff time.Duration,
+	tracker *messageFailureTracker,
+	deadLetterSink DeadLetterSink,
+	reg *metrics.Registry,
+) error {
+	if msg.MessageID == nil || msg.PopReceipt == nil {
+		log.Printf("Received message with nil MessageID or PopReceipt, skipping")
+		return nil
+	}
+
+	var dequeueCount int64
+	if msg.DequeueCount != nil {
+		dequeueCount = *msg.DequeueCount
+	}
+
+	timestamps := tracker.record(*msg.MessageID, time.Now())
+
+	if dequeueCount >= int64(maxDeliveryCount) {
+		tracker.forget(*msg.MessageID)
+
+		if deadLetterSink != nil {
+			record := DeadLetterRecord{
+				QueueName:         queueName,
+				BlobName:          blobName,
+				MessageText:       blobName,
+				LastError:         procErr.Error(),
+				Attempts:          int(dequeueCount),
+				AttemptTimestamps: timestamps,
+				BlobMetadata:      blobMetadata,
+				DeadLetteredAt:    time.Now(),
+			}
+			if err := deadLetterSink.Send(ctx, record); err != nil {
+				return fmt.Errorf("failed to dead-letter message for blob %s: %w", blobName, err)
+			}
+			reg.ObserveDLQ(queueName)
+			log.Printf("Blob %s moved to dead-letter queue after %d delivery attempts: %v", blobName, dequeueCount, procErr)
+		} else {
+			log.Printf("Blob %s exceeded max delivery count (%d) but no dead-letter sink is configured, leaving in queue", blobName, maxDeliveryCount)
+			return nil
+		}
+
+		if _, err := queueClient.DeleteMessage(ctx, *msg.MessageID, *msg.PopReceipt, nil); err != nil {
+			return fmt.Errorf("failed to remove dead-lettered message for blob %s: %w", blobName, err)
+		}
+
+		return nil
+	}
+
+	backoff := backoffWithJitter(dequeueCount, baseBackoff, maxBackoff)
+	visibilityTimeout := int32(backoff.Seconds())
+	messageText := blobName
+	if msg.MessageText != nil {
+		messageText = *msg.MessageText
+	}
+	if _, err := queueClient.UpdateMessage(ctx, *msg.MessageID, *msg.PopReceipt, visibilityTimeout, messageText, nil); err != nil {
+		log.Printf("Failed to extend visibility timeout for blob %s: %v", blobName, err)
+	}
+
+	return nil
+}
+
+// RequeueFromDeadLetter lets operators inspect and replay dead-lettered
+// messages matching filter back onto targetQueue (typically the message's
+// original queue) without editing queue storage by hand.
+func RequeueFromDeadLetter(ctx context.Context, sink DeadLetterSink, filter DeadLetterFilter, targetQueue string) (int, error) {
+	return sink.Requeue(ctx, filter, targetQueue)
+}
+
+// backoffWithJitter returns an exponential backoff delay based on the
+// number of prior delivery attempts, capped at maxBackoff and jittered by
+// up to 20% to avoid synchronized retries across messages.
+func backoffWithJitter(attempt int64, base, max time.Duration) time.Duration {
+	if attempt < 0 {
+		attempt = 0
+	}
+
+	delay := base
+	for i := int64(0); i < attempt; i++ {
+		delay *= 2
+		if delay >= max {
+			delay = max
+			break
+		}
+	}
+
+	jitter := time.Duration(rand.Int63n(int64(delay) / 5 + 1))
+	return delay + jitter
+}
@@ -15,6 +15,7 @@ import (
 	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob"
 	"github.com/Azure/azure-sdk-for-go/sdk/storage/azqueue"
 	"github.com/CAST-Intelligence/elysium-usv/internal/config"
+	"github.com/CAST-Intelligence/elysium-usv/internal/metrics"
 	"github.com/jlaffaye/ftp"
 )
 
@@ -29,11 +30,12 @@ type FTPWorker struct {
 	tempDir         string
 }
 
-// NewFTPWorker creates a new worker for FTP file watching
+// NewFTPWorker creates a new worker for FTP file watching. reg may be nil.
 func NewFTPWorker(
 	cfg *config.Config,
 	blobClient *azblob.Client,
 	queueClient *azqueue.ServiceClient,
+	reg *metrics.Registry,
 ) *FTPWorker {
 	// Create a temporary directory for downloaded files if FTPWatchDir is not specified
 	tempDir := cfg.FTPWatchDir
@@ -46,6 +48,16 @@ func NewFTPWorker(
 		}
 	}
 
+	// Each in-flight streamed upload holds roughly BlockSize*Concurrency
+	// bytes in memory; clamp concurrency down if that would exceed the
+	// configured ceiling rather than silently using more memory than
+	// operators asked for.
+	if maxConcurrency := cfg.MaxMemoryPerTransfer / cfg.UploadBlockSize; maxConcurrency > 0 && int64(cfg.UploadConcurrency) > maxConcurrency {
+		log.Printf("Clamping upload concurrency from %d to %d to respect MaxMemoryPerTransfer (%d bytes)",
+			cfg.UploadConcurrency, maxConcurrency, cfg.MaxMemoryPerTransfer)
+		cfg.UploadConcurrency = int(maxConcurrency)
+	}
+
 	fw := &FTPWorker{
 		config:          cfg,
 		blobClient:      blobClient,
@@ -62,6 +74,7 @@ func NewFTPWorker(
 		cfg.FTPPollInterval,
 		cfg.ProcessingBatchSize,
 		cfg.OperationRetryCount,
+		reg,
 	)
 
 	fw.worker = worker
@@ -88,6 +101,22 @@ func (fw *FTPWorker) LastRun() time.Time {
 	return fw.worker.LastRun()
 }
 
+// Pause, Resume, IsPaused, Interval, BatchSize, and RetryCount forward to
+// the underlying Worker, so FTPWorker satisfies server.ManagedWorker.
+func (fw *FTPWorker) Pause()                { fw.worker.Pause() }
+func (fw *FTPWorker) Resume()                { fw.worker.Resume() }
+func (fw *FTPWorker) IsPaused() bool         { return fw.worker.IsPaused() }
+func (fw *FTPWorker) Interval() time.Duration { return fw.worker.Interval() }
+func (fw *FTPWorker) BatchSize() int         { return fw.worker.BatchSize() }
+func (fw *FTPWorker) RetryCount() int        { return fw.worker.RetryCount() }
+
+// Reconfigure applies new runtime tunables to the underlying Worker.
+// visibilityTimeout is ignored; FTPWorker has no visibility timeout
+// tunable of its own.
+func (fw *FTPWorker) Reconfigure(interval time.Duration, batchSize, retryCount int, visibilityTimeout time.Duration) {
+	fw.worker.Reconfigure(interval, batchSize, retryCount)
+}
+
 // connectFTP establishes a connection to the FTP server with retries
 func (fw *FTPWorker) connectFTP(ctx context.Context) (*ftp.ServerConn, error) {
 	var conn *ftp.ServerConn
@@ -228,44 +257,6 @@ func (fw *FTPWorker) processFTPFiles(ctx context.Context, batchSize int) error {
 			continue
 		}
 
-		// Download data file
-		dataFilePath := filepath.Join(fw.tempDir, dataFileName)
-		dataFileOut, err := os.Create(dataFilePath)
-		if err != nil {
-			log.Printf("Failed to create data file: %v", err)
-			continue
-		}
-
-		resp, err = conn.Retr(dataFileName)
-		if err != nil {
-			log.Printf("Failed to download data file: %v", err)
-			dataFileOut.Close()
-			os.Remove(dataFilePath)
-			continue
-		}
-
-		_, err = io.Copy(dataFileOut, resp)
-		resp.Close()
-		dataFileOut.Close()
-		if err != nil {
-			log.Printf("Failed to save data file: %v", err)
-			os.Remove(dataFilePath)
-			continue
-		}
-
-		// Verify MD5 hash
-		calculatedHash, err := calculateMD5(dataFilePath)
-		if err != nil {
-			log.Printf("Failed to calculate MD5 for data file: %v", err)
-			continue
-		}
-
-		if calculatedHash != hash {
-			log.Printf("MD5 hash mismatch for %s - expected: %s, got: %s",
-				dataFileName, hash, calculatedHash)
-			continue
-		}
-
 		// Extract vessel ID
 		vesselID := "unknown"
 		if ekiParts := strings.Split(dataFileName, "-EKI"); len(ekiParts) > 1 {
@@ -276,35 +267,28 @@ func (fw *FTPWorker) processFTPFiles(ctx context.Context, batchSize int) error {
 			vesselID = fmt.Sprintf("VESSEL%s", vesselIDPart)
 		}
 
-		// Upload to Azure
 		blobName := fmt.Sprintf("%s/%s", vesselID, dataFileName)
-		log.Printf("Uploading %s with MD5 %s", dataFileName, hash)
-
-		containerClient := fw.blobClient.ServiceClient().NewContainerClient(fw.containerName)
-		blockBlobClient := containerClient.NewBlockBlobClient(blobName)
-
-		// Prepare metadata
-		metadata := map[string]*string{
-			"checksum":          stringPtr(hash),
-			"vesselid":          stringPtr(vesselID),
-			"timestamp":         stringPtr(time.Now().UTC().Format(time.RFC3339)),
-			"checksumAlgorithm": stringPtr("MD5"),
-		}
-
-		// Read file
-		file, err := os.ReadFile(dataFilePath)
+		log.Printf("Uploading %s, expecting MD5 %s", dataFileName, hash)
+
+		// Stream the data file from FTP directly into blob storage: the
+		// Retr reader feeds an io.Pipe whose reader is consumed by
+		// UploadStream, while the bytes are tee'd into an MD5 hasher so we
+		// never buffer the whole (potentially multi-GB) file in memory or
+		// on disk. The FTP response isn't seekable, so on a retry we just
+		// re-issue Retr rather than resuming a partial stream.
+		calculatedHash, err := fw.streamUploadFromFTP(ctx, conn, dataFileName, blobName, vesselID)
 		if err != nil {
-			log.Printf("Failed to read file %s: %v", dataFilePath, err)
+			log.Printf("Failed to stream %s to blob storage: %v", dataFileName, err)
 			continue
 		}
 
-		// Upload file
-		options := &azblob.UploadBufferOptions{
-			Metadata: metadata,
-		}
-		_, err = blockBlobClient.UploadBuffer(ctx, file, options)
-		if err != nil {
-			log.Printf("Failed to upload blob %s: %v", blobName, err)
+		if calculatedHash != hash {
+			log.Printf("MD5 hash mismatch for %s - expected: %s, got: %s",
+				dataFileName, hash, calculatedHash)
+			if _, delErr := fw.blobClient.ServiceClient().NewContainerClient(fw.containerName).
+				NewBlobClient(blobName).Delete(ctx, nil); delErr != nil {
+				log.Printf("Failed to remove blob %s after checksum mismatch: %v", blobName, delErr)
+			}
 			continue
 		}
 
@@ -319,8 +303,9 @@ func (fw *FTPWorker) processFTPFiles(ctx context.Context, batchSize int) error {
 		log.Printf("Successfully processed %s", dataFileName)
 		processedCount++
 
-		// Move files to processed directory
-		os.Rename(dataFilePath, filepath.Join(processedDir, dataFileName))
+		// The MD5 sidecar was downloaded to disk for comparison; the data
+		// file itself was streamed straight to blob storage and never
+		// landed locally, so only the sidecar moves to processed.
 		os.Rename(md5FilePath, filepath.Join(processedDir, entry.Name))
 
 		// Delete files from FTP server if successful
@@ -342,6 +327,95 @@ func (fw *FTPWorker) processFTPFiles(ctx context.Context, batchSize int) error {
 	return nil
 }
 
+// streamUploadFromFTP retrieves name from the FTP server and uploads it to
+// blob storage without buffering the whole file, retrying up to
+// fw.config.FTPRetryCount times on failure. It returns the MD5 hash
+// computed while streaming so the caller can verify it against the MD5
+// sidecar without a second pass over the data.
+func (fw *FTPWorker) streamUploadFromFTP(ctx context.Context, conn *ftp.ServerConn, name, blobName, vesselID string) (string, error) {
+	var lastErr error
+
+	for attempt := 0; attempt <= fw.config.FTPRetryCount; attempt++ {
+		if attempt > 0 {
+			log.Printf("Retrying upload of %s (attempt %d/%d)...", name, attempt, fw.config.FTPRetryCount)
+			select {
+			case <-ctx.Done():
+				return "", ctx.Err()
+			case <-time.After(fw.config.FTPRetryDelay):
+			}
+		}
+
+		resp, err := conn.Retr(name)
+		if err != nil {
+			lastErr = fmt.Errorf("failed to retrieve %s: %w", name, err)
+			continue
+		}
+
+		hash, err := fw.pipeToBlob(ctx, resp, blobName, vesselID)
+		resp.Close()
+		if err != nil {
+			lastErr = err
+			continue
+		}
+
+		return hash, nil
+	}
+
+	return "", lastErr
+}
+
+// pipeToBlob tees src into an MD5 hasher and an io.Pipe whose reader feeds
+// blockblob.Client.UploadStream, so the full file is never held in memory.
+func (fw *FTPWorker) pipeToBlob(ctx context.Context, src io.Reader, blobName, vesselID string) (string, error) {
+	pr, pw := io.Pipe()
+	hasher := md5.New()
+
+	go func() {
+		_, copyErr := io.Copy(io.MultiWriter(pw, hasher), src)
+		pw.CloseWithError(copyErr)
+	}()
+
+	metadata := map[string]*string{
+		"vesselid":          stringPtr(vesselID),
+		"timestamp":         stringPtr(time.Now().UTC().Format(time.RFC3339)),
+		"checksumAlgorithm": stringPtr("MD5"),
+	}
+
+	containerClient := fw.blobClient.ServiceClient().NewContainerClient(fw.containerName)
+	blockBlobClient := containerClient.NewBlockBlobClient(blobName)
+
+	options := &azblob.UploadStreamOptions{
+		BlockSize:   fw.config.UploadBlockSize,
+		Concurrency: fw.config.UploadConcurrency,
+		Metadata:    metadata,
+	}
+
+	if _, err := blockBlobClient.UploadStream(ctx, pr, options); err != nil {
+		pr.CloseWithError(err)
+		return "", fmt.Errorf("failed to upload blob %s: %w", blobName, err)
+	}
+
+	checksum := hex.EncodeToString(hasher.Sum(nil))
+
+	// Record the checksum we computed while streaming, now that we know
+	// the upload succeeded.
+	blobClient := containerClient.NewBlobClient(blobName)
+	props, err := blobClient.GetProperties(ctx, nil)
+	if err != nil {
+		return checksum, fmt.Errorf("failed to read blob properties for %s: %w", blobName, err)
+	}
+	updatedMetadata := map[string]*string{}
+	for k, v := range props.Metadata {
+		updatedMetadata[k] = v
+	}
+	updatedMetadata["checksum"] = stringPtr(checksum)
+	if _, err := blobClient.SetMetadata(ctx, updatedMetadata, nil); err != nil {
+		return checksum, fmt.Errorf("failed to set checksum metadata for %s: %w", blobName, err)
+	}
+
+	return checksum, nil
+}
+
 // readMD5FromFile reads an MD5 hash from a file
 func readMD5FromFile(filePath string) (string, error) {
 	data, err := os.ReadFile(filePath)
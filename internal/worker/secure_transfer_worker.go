@@ -0,0 +1,605 @@
+package worker
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azqueue"
+	"github.com/CAST-Intelligence/elysium-usv/internal/config"
+	"github.com/CAST-Intelligence/elysium-usv/internal/metrics"
+	"github.com/fsnotify/fsnotify"
+	"github.com/jlaffaye/ftp"
+	"github.com/pkg/sftp"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+// SecureTransferWorker watches a remote host for files with MD5 hash
+// companions over SFTP or FTPS, replacing the plaintext FTP transport used
+// by FTPWorker for regulated vessel data. In addition to polling, it can
+// run an fsnotify watcher against a locally mounted share so dropped files
+// are picked up immediately instead of waiting for the next poll.
+type SecureTransferWorker struct {
+	worker          *Worker
+	config          *config.Config
+	blobClient      *azblob.Client
+	queueClient     *azqueue.ServiceClient
+	containerName   string
+	validationQueue string
+	tempDir         string
+	fsEvents        chan string
+	fsWatcher       *fsnotify.Watcher
+}
+
+// NewSecureTransferWorker creates a new worker for SFTP/FTPS file watching.
+// reg may be nil.
+func NewSecureTransferWorker(
+	cfg *config.Config,
+	blobClient *azblob.Client,
+	queueClient *azqueue.ServiceClient,
+	reg *metrics.Registry,
+) (*SecureTransferWorker, error) {
+	tempDir := cfg.TransferWatchDir
+	if tempDir == "" {
+		var err error
+		tempDir, err = os.MkdirTemp("", "secure-transfer-worker")
+		if err != nil {
+			log.Printf("Failed to create temp directory: %v", err)
+			tempDir = os.TempDir()
+		}
+	}
+
+	stw := &SecureTransferWorker{
+		config:          cfg,
+		blobClient:      blobClient,
+		queueClient:     queueClient,
+		containerName:   cfg.BlobContainerName,
+		validationQueue: cfg.ValidationQueueName,
+		tempDir:         tempDir,
+	}
+
+	stw.worker = NewWorker(
+		"secure-transfer",
+		stw.processSecureFiles,
+		cfg.TransferPollInterval,
+		cfg.ProcessingBatchSize,
+		cfg.OperationRetryCount,
+		reg,
+	)
+
+	if cfg.FSNotifyEnabled && cfg.TransferWatchDir != "" {
+		// stw.worker must already exist so the watcher goroutine can wake it
+		// immediately on an event, rather than only logging and waiting for
+		// the next poll.
+		watcher, events, err := startFSWatcher(cfg.TransferWatchDir, stw.worker.Wake)
+		if err != nil {
+			return nil, fmt.Errorf("failed to start filesystem watcher: %w", err)
+		}
+		stw.fsWatcher = watcher
+		stw.fsEvents = events
+	}
+
+	return stw, nil
+}
+
+// Start starts the secure transfer worker.
+func (stw *SecureTransferWorker) Start() {
+	stw.worker.Start()
+}
+
+// Stop stops the secure transfer worker.
+func (stw *SecureTransferWorker) Stop() {
+	stw.worker.Stop()
+	if stw.fsWatcher != nil {
+		stw.fsWatcher.Close()
+	}
+}
+
+// Status returns the worker status.
+func (stw *SecureTransferWorker) Status() string {
+	return stw.worker.Status()
+}
+
+// LastRun returns the time of the last run.
+func (stw *SecureTransferWorker) LastRun() time.Time {
+	return stw.worker.LastRun()
+}
+
+// Pause, Resume, IsPaused, Interval, BatchSize, and RetryCount forward to
+// the underlying Worker, so SecureTransferWorker satisfies
+// server.ManagedWorker.
+func (stw *SecureTransferWorker) Pause()                { stw.worker.Pause() }
+func (stw *SecureTransferWorker) Resume()                { stw.worker.Resume() }
+func (stw *SecureTransferWorker) IsPaused() bool         { return stw.worker.IsPaused() }
+func (stw *SecureTransferWorker) Interval() time.Duration { return stw.worker.Interval() }
+func (stw *SecureTransferWorker) BatchSize() int         { return stw.worker.BatchSize() }
+func (stw *SecureTransferWorker) RetryCount() int        { return stw.worker.RetryCount() }
+
+// Reconfigure applies new runtime tunables to the underlying Worker.
+// visibilityTimeout is ignored; SecureTransferWorker has no visibility
+// timeout tunable of its own.
+func (stw *SecureTransferWorker) Reconfigure(interval time.Duration, batchSize, retryCount int, visibilityTimeout time.Duration) {
+	stw.worker.Reconfigure(interval, batchSize, retryCount)
+}
+
+// startFSWatcher watches dir for newly created files, reports their names
+// on the returned channel, and calls wake on every such event so the
+// fsnotify path doesn't have to wait for the next poll interval to get
+// picked up.
+func startFSWatcher(dir string, wake func()) (*fsnotify.Watcher, chan string, error) {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := watcher.Add(dir); err != nil {
+		watcher.Close()
+		return nil, nil, fmt.Errorf("failed to watch %s: %w", dir, err)
+	}
+
+	events := make(chan string, 64)
+	go func() {
+		for {
+			select {
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				if event.Op&(fsnotify.Create|fsnotify.Write) != 0 {
+					events <- filepath.Base(event.Name)
+					wake()
+				}
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				log.Printf("Filesystem watcher error on %s: %v", dir, err)
+			}
+		}
+	}()
+
+	return watcher, events, nil
+}
+
+// secureFileLister abstracts listing and retrieving files over whichever
+// transport is configured, so processSecureFiles doesn't need to know
+// whether it is talking to SFTP or FTPS.
+type secureFileLister interface {
+	list(ctx context.Context) ([]string, error)
+	retrieve(ctx context.Context, name string, dst io.Writer) error
+	remove(ctx context.Context, name string) error
+	close() error
+}
+
+// remoteHasher is an optional capability for secureFileLister
+// implementations that can compute a file's hash on the server itself. When
+// a lister supports it, processPair checks the remote hash against the MD5
+// sidecar before downloading the data file, so a corrupted or mismatched
+// file is rejected without paying for a full download that would only be
+// discarded. Only sftpLister implements this today, via the
+// hash@openssh.com extension.
+type remoteHasher interface {
+	remoteHash(ctx context.Context, name string) (string, error)
+}
+
+// processSecureFiles is the main worker function. It drains any pending
+// fsnotify events first (for immediate local pickup), then lists the
+// remote directory for the MD5 sidecar pattern already used by FTPWorker.
+func (stw *SecureTransferWorker) processSecureFiles(ctx context.Context, batchSize int) error {
+	if _, err := os.Stat(stw.tempDir); os.IsNotExist(err) {
+		if err := os.MkdirAll(stw.tempDir, 0755); err != nil {
+			return fmt.Errorf("failed to create temp directory: %w", err)
+		}
+	}
+
+	processedDir := filepath.Join(stw.tempDir, "processed")
+	if _, err := os.Stat(processedDir); os.IsNotExist(err) {
+		if err := os.MkdirAll(processedDir, 0755); err != nil {
+			return fmt.Errorf("failed to create processed directory: %w", err)
+		}
+	}
+
+	stw.drainFSEvents()
+
+	lister, err := stw.connect(ctx)
+	if err != nil {
+		return err
+	}
+	defer lister.close()
+
+	entries, err := lister.list(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list files: %w", err)
+	}
+
+	log.Printf("Found %d files on %s server", len(entries), stw.config.TransferProtocol)
+
+	processedCount := 0
+	for _, name := range entries {
+		if processedCount >= batchSize {
+			break
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if !strings.HasSuffix(name, ".md5") {
+			continue
+		}
+
+		dataFileName := strings.TrimSuffix(name, ".md5")
+		if !containsName(entries, dataFileName) {
+			log.Printf("Data file not found for MD5 file: %s", name)
+			continue
+		}
+
+		if err := stw.processPair(ctx, lister, processedDir, name, dataFileName); err != nil {
+			log.Printf("Failed to process %s: %v", dataFileName, err)
+			continue
+		}
+
+		processedCount++
+	}
+
+	if processedCount > 0 {
+		log.Printf("Processed %d files", processedCount)
+	}
+
+	return nil
+}
+
+// drainFSEvents logs any fsnotify events seen since the last poll; the
+// watcher goroutine (see startFSWatcher) has already called Worker.Wake for
+// each of them by the time this runs, so the immediate-pickup behavior
+// doesn't depend on this loop at all. File processing itself still goes
+// through the list/retrieve path above so the MD5-verification and upload
+// logic only needs to live in one place.
+func (stw *SecureTransferWorker) drainFSEvents() {
+	if stw.fsEvents == nil {
+		return
+	}
+	for {
+		select {
+		case name := <-stw.fsEvents:
+			log.Printf("Filesystem watcher observed new file: %s", name)
+		default:
+			return
+		}
+	}
+}
+
+func containsName(names []string, target string) bool {
+	for _, n := range names {
+		if n == target {
+			return true
+		}
+	}
+	return false
+}
+
+// processPair downloads the MD5 sidecar and data file, verifies the hash,
+// uploads the data file to blob storage, queues validation, and cleans up
+// both the remote and local copies.
+func (stw *SecureTransferWorker) processPair(ctx context.Context, lister secureFileLister, processedDir, md5Name, dataFileName string) error {
+	md5FilePath := filepath.Join(stw.tempDir, md5Name)
+	md5File, err := os.Create(md5FilePath)
+	if err != nil {
+		return fmt.Errorf("failed to create MD5 file: %w", err)
+	}
+	if err := lister.retrieve(ctx, md5Name, md5File); err != nil {
+		md5File.Close()
+		os.Remove(md5FilePath)
+		return fmt.Errorf("failed to download MD5 file: %w", err)
+	}
+	md5File.Close()
+
+	expectedHash, err := readMD5FromFile(md5FilePath)
+	if err != nil {
+		return fmt.Errorf("failed to read MD5 from file: %w", err)
+	}
+
+	// If the transport can hash remotely, check it against the sidecar
+	// before downloading - a mismatch is then caught without paying for a
+	// full download that would only be thrown away.
+	remoteVerified := false
+	if hasher, ok := lister.(remoteHasher); ok {
+		remoteSum, err := hasher.remoteHash(ctx, dataFileName)
+		if err != nil {
+			log.Printf("Remote hash check unavailable for %s, falling back to download-then-hash: %v", dataFileName, err)
+		} else if !strings.EqualFold(remoteSum, expectedHash) {
+			return fmt.Errorf("MD5 hash mismatch for %s - expected: %s, got: %s (verified server-side, no download needed)", dataFileName, expectedHash, remoteSum)
+		} else {
+			remoteVerified = true
+		}
+	}
+
+	dataFilePath := filepath.Join(stw.tempDir, dataFileName)
+	dataFile, err := os.Create(dataFilePath)
+	if err != nil {
+		return fmt.Errorf("failed to create data file: %w", err)
+	}
+	if err := lister.retrieve(ctx, dataFileName, dataFile); err != nil {
+		dataFile.Close()
+		os.Remove(dataFilePath)
+		return fmt.Errorf("failed to download data file: %w", err)
+	}
+	dataFile.Close()
+
+	if !remoteVerified {
+		calculatedHash, err := calculateMD5(dataFilePath)
+		if err != nil {
+			return fmt.Errorf("failed to calculate MD5 for data file: %w", err)
+		}
+		if calculatedHash != expectedHash {
+			return fmt.Errorf("MD5 hash mismatch for %s - expected: %s, got: %s", dataFileName, expectedHash, calculatedHash)
+		}
+	}
+
+	vesselID := extractVesselID(dataFileName)
+	blobName := fmt.Sprintf("%s/%s", vesselID, dataFileName)
+
+	containerClient := stw.blobClient.ServiceClient().NewContainerClient(stw.containerName)
+	blockBlobClient := containerClient.NewBlockBlobClient(blobName)
+
+	file, err := os.ReadFile(dataFilePath)
+	if err != nil {
+		return fmt.Errorf("failed to read file %s: %w", dataFilePath, err)
+	}
+
+	metadata := map[string]*string{
+		"checksum":          stringPtr(expectedHash),
+		"vesselid":          stringPtr(vesselID),
+		"timestamp":         stringPtr(time.Now().UTC().Format(time.RFC3339)),
+		"checksumAlgorithm": stringPtr("MD5"),
+	}
+
+	options := &azblob.UploadBufferOptions{Metadata: metadata}
+	if _, err := blockBlobClient.UploadBuffer(ctx, file, options); err != nil {
+		return fmt.Errorf("failed to upload blob %s: %w", blobName, err)
+	}
+
+	queueClient := stw.queueClient.NewQueueClient(stw.validationQueue)
+	if _, err := queueClient.EnqueueMessage(ctx, blobName, nil); err != nil {
+		return fmt.Errorf("failed to queue validation for %s: %w", blobName, err)
+	}
+
+	log.Printf("Successfully processed %s", dataFileName)
+
+	os.Rename(dataFilePath, filepath.Join(processedDir, dataFileName))
+	os.Rename(md5FilePath, filepath.Join(processedDir, md5Name))
+
+	if err := lister.remove(ctx, dataFileName); err != nil {
+		log.Printf("Failed to delete data file from remote server: %v", err)
+	}
+	if err := lister.remove(ctx, md5Name); err != nil {
+		log.Printf("Failed to delete MD5 file from remote server: %v", err)
+	}
+
+	return nil
+}
+
+// extractVesselID mirrors FTPWorker's vessel ID heuristic so blob naming
+// stays consistent regardless of which worker ingested the file.
+func extractVesselID(dataFileName string) string {
+	if ekiParts := strings.Split(dataFileName, "-EKI"); len(ekiParts) > 1 {
+		ekiID := strings.Split(ekiParts[1], ".")[0]
+		return fmt.Sprintf("EKI%s", ekiID)
+	}
+	if vesselParts := strings.Split(dataFileName, "VESSEL"); len(vesselParts) > 1 {
+		vesselIDPart := strings.Split(vesselParts[1], "_")[0]
+		return fmt.Sprintf("VESSEL%s", vesselIDPart)
+	}
+	return "unknown"
+}
+
+// connect dials the configured transport.
+func (stw *SecureTransferWorker) connect(ctx context.Context) (secureFileLister, error) {
+	switch strings.ToLower(stw.config.TransferProtocol) {
+	case "sftp":
+		return dialSFTP(ctx, stw.config)
+	case "ftps":
+		return dialFTPS(ctx, stw.config)
+	default:
+		return nil, fmt.Errorf("unsupported transfer protocol: %q", stw.config.TransferProtocol)
+	}
+}
+
+// sftpLister implements secureFileLister over an SSH/SFTP connection.
+type sftpLister struct {
+	sshConn  *ssh.Client
+	client   *sftp.Client
+	hashable bool
+}
+
+func dialSFTP(ctx context.Context, cfg *config.Config) (*sftpLister, error) {
+	var authMethods []ssh.AuthMethod
+	if cfg.SFTPPrivateKeyPath != "" {
+		keyBytes, err := os.ReadFile(cfg.SFTPPrivateKeyPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read SFTP private key: %w", err)
+		}
+		signer, err := ssh.ParsePrivateKey(keyBytes)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse SFTP private key: %w", err)
+		}
+		authMethods = append(authMethods, ssh.PublicKeys(signer))
+	}
+	if cfg.TransferPassword != "" {
+		authMethods = append(authMethods, ssh.Password(cfg.TransferPassword))
+	}
+
+	hostKeyCallback, err := hostKeyCallback(cfg.SFTPKnownHostsPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to configure host key verification: %w", err)
+	}
+
+	sshConfig := &ssh.ClientConfig{
+		User:            cfg.TransferUser,
+		Auth:            authMethods,
+		HostKeyCallback: hostKeyCallback,
+		Timeout:         30 * time.Second,
+	}
+
+	addr := fmt.Sprintf("%s:%d", cfg.TransferHost, cfg.TransferPort)
+	conn, err := ssh.Dial("tcp", addr, sshConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to dial SSH server: %w", err)
+	}
+
+	client, err := sftp.NewClient(conn)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to create SFTP client: %w", err)
+	}
+
+	_, hashable := client.HasExtension("hash@openssh.com")
+	if hashable {
+		log.Printf("SFTP server at %s supports hash@openssh.com; will verify against the MD5 "+
+			"sidecar server-side before downloading", cfg.TransferHost)
+	}
+
+	return &sftpLister{sshConn: conn, client: client, hashable: hashable}, nil
+}
+
+func (l *sftpLister) list(ctx context.Context) ([]string, error) {
+	entries, err := l.client.ReadDir(".")
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(entries))
+	for _, e := range entries {
+		if !e.IsDir() {
+			names = append(names, e.Name())
+		}
+	}
+	return names, nil
+}
+
+func (l *sftpLister) retrieve(ctx context.Context, name string, dst io.Writer) error {
+	src, err := l.client.Open(name)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	_, err = io.Copy(dst, src)
+	return err
+}
+
+func (l *sftpLister) remove(ctx context.Context, name string) error {
+	return l.client.Remove(name)
+}
+
+func (l *sftpLister) close() error {
+	l.client.Close()
+	return l.sshConn.Close()
+}
+
+// remoteHash computes the MD5 of name on the server via the
+// hash@openssh.com extension, without transferring its contents.
+func (l *sftpLister) remoteHash(ctx context.Context, name string) (string, error) {
+	if !l.hashable {
+		return "", fmt.Errorf("server does not support hash@openssh.com")
+	}
+	sum, err := l.client.Hash(name, sftp.HashAlgoMD5)
+	if err != nil {
+		return "", fmt.Errorf("failed to compute remote hash for %s: %w", name, err)
+	}
+	return sum, nil
+}
+
+// hostKeyCallback builds a knownhosts-backed callback for host key
+// verification. A missing known_hosts file is a hard configuration error:
+// SFTP for regulated vessel data must never fall back to InsecureIgnoreHostKey.
+func hostKeyCallback(knownHostsPath string) (ssh.HostKeyCallback, error) {
+	if knownHostsPath == "" {
+		return nil, fmt.Errorf("SFTP_KNOWN_HOSTS_PATH is required for host key verification")
+	}
+	return knownhosts.New(knownHostsPath)
+}
+
+// ftpsLister implements secureFileLister over explicit-TLS FTP (FTPS).
+type ftpsLister struct {
+	conn *ftp.ServerConn
+}
+
+func dialFTPS(ctx context.Context, cfg *config.Config) (*ftpsLister, error) {
+	tlsConfig := &tls.Config{
+		ServerName: cfg.TransferHost,
+	}
+
+	if cfg.FTPSCACertPath != "" {
+		caCert, err := os.ReadFile(cfg.FTPSCACertPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read FTPS CA certificate: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(caCert) {
+			return nil, fmt.Errorf("failed to parse FTPS CA certificate")
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if cfg.FTPSClientCertPath != "" && cfg.FTPSClientKeyPath != "" {
+		cert, err := tls.LoadX509KeyPair(cfg.FTPSClientCertPath, cfg.FTPSClientKeyPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load FTPS client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	addr := fmt.Sprintf("%s:%d", cfg.TransferHost, cfg.TransferPort)
+	conn, err := ftp.Dial(addr,
+		ftp.DialWithTimeout(30*time.Second),
+		ftp.DialWithExplicitTLS(tlsConfig),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to FTPS server: %w", err)
+	}
+
+	if err := conn.Login(cfg.TransferUser, cfg.TransferPassword); err != nil {
+		conn.Quit()
+		return nil, fmt.Errorf("failed to login to FTPS server: %w", err)
+	}
+
+	return &ftpsLister{conn: conn}, nil
+}
+
+func (l *ftpsLister) list(ctx context.Context) ([]string, error) {
+	entries, err := l.conn.List(".")
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(entries))
+	for _, e := range entries {
+		if e.Type != ftp.EntryTypeFolder {
+			names = append(names, e.Name)
+		}
+	}
+	return names, nil
+}
+
+func (l *ftpsLister) retrieve(ctx context.Context, name string, dst io.Writer) error {
+	resp, err := l.conn.Retr(name)
+	if err != nil {
+		return err
+	}
+	defer resp.Close()
+	_, err = io.Copy(dst, resp)
+	return err
+}
+
+func (l *ftpsLister) remove(ctx context.Context, name string) error {
+	return l.conn.Delete(name)
+}
+
+func (l *ftpsLister) close() error {
+	return l.conn.Quit()
+}
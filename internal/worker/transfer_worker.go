@@ -2,6 +2,7 @@ package worker
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"strings"
@@ -9,34 +10,55 @@ import (
 
 	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob"
 	"github.com/Azure/azure-sdk-for-go/sdk/storage/azqueue"
-	"github.com/CAST-Intelligence/elysium-usv/internal/aws"
 	"github.com/CAST-Intelligence/elysium-usv/internal/config"
+	"github.com/CAST-Intelligence/elysium-usv/internal/metrics"
+	"github.com/CAST-Intelligence/elysium-usv/internal/storage"
 	"github.com/CAST-Intelligence/elysium-usv/internal/transfer"
 )
 
-// TransferWorker processes validated blobs for transfer to S3
+// TransferWorker processes validated blobs for transfer to every
+// configured storage.Volume destination
 type TransferWorker struct {
-	worker        *Worker
-	blobClient    *azblob.Client
-	queueClient   *azqueue.ServiceClient
-	s3Client      *aws.S3Client
-	containerName string
-	queueName     string
+	worker           *Worker
+	uploadReconciler *Worker
+	blobClient       *azblob.Client
+	queueClient      *azqueue.ServiceClient
+	volumes          []storage.Volume
+	containerName    string
+	queueName        string
+	deadLetterSink   DeadLetterSink
+	maxDeliveryCount int32
+	baseBackoff      time.Duration
+	maxBackoff       time.Duration
+	failureTracker   *messageFailureTracker
+	uploadStaleTTL   time.Duration
+	raceWindow       time.Duration
+	metrics          *metrics.Registry
 }
 
-// NewTransferWorker creates a new worker for S3 transfers
+// NewTransferWorker creates a new worker that transfers validated blobs to
+// every destination in volumes. reg may be nil.
 func NewTransferWorker(
 	cfg *config.Config,
 	blobClient *azblob.Client,
 	queueClient *azqueue.ServiceClient,
-	s3Client *aws.S3Client,
+	volumes []storage.Volume,
+	reg *metrics.Registry,
 ) *TransferWorker {
 	tw := &TransferWorker{
-		blobClient:    blobClient,
-		queueClient:   queueClient,
-		s3Client:      s3Client,
-		containerName: cfg.BlobContainerName,
-		queueName:     cfg.TransferQueueName,
+		blobClient:       blobClient,
+		queueClient:      queueClient,
+		volumes:          volumes,
+		containerName:    cfg.BlobContainerName,
+		queueName:        cfg.TransferQueueName,
+		deadLetterSink:   NewAzureQueueDeadLetterSink(queueClient, cfg.DeadLetterQueueName),
+		maxDeliveryCount: cfg.MaxDeliveryCount,
+		baseBackoff:      cfg.DeadLetterBaseBackoff,
+		maxBackoff:       cfg.DeadLetterMaxBackoff,
+		failureTracker:   newMessageFailureTracker(),
+		uploadStaleTTL:   cfg.UploadStaleTTL,
+		raceWindow:       cfg.RaceWindow,
+		metrics:          reg,
 	}
 
 	// Create the underlying worker
@@ -46,20 +68,37 @@ func NewTransferWorker(
 		30*time.Second, // Poll every 30 seconds
 		cfg.ProcessingBatchSize,
 		cfg.OperationRetryCount,
+		reg,
 	)
-
 	tw.worker = worker
+
+	// Create the second worker that sweeps for resumable multipart uploads
+	// left dangling by a crash or restart: its own interval, independent of
+	// the transfer-queue poll above, since it only needs to run as often as
+	// a stale upload is actually worth aborting.
+	uploadReconciler := NewWorker(
+		"upload-reconciler",
+		tw.processUploadReconciliation,
+		cfg.UploadReconcileInterval,
+		cfg.ProcessingBatchSize,
+		cfg.OperationRetryCount,
+		reg,
+	)
+	tw.uploadReconciler = uploadReconciler
+
 	return tw
 }
 
-// Start starts the transfer worker
+// Start starts the transfer worker and its upload-reconciliation sweep
 func (tw *TransferWorker) Start() {
 	tw.worker.Start()
+	tw.uploadReconciler.Start()
 }
 
-// Stop stops the transfer worker
+// Stop stops the transfer worker and its upload-reconciliation sweep
 func (tw *TransferWorker) Stop() {
 	tw.worker.Stop()
+	tw.uploadReconciler.Stop()
 }
 
 // Status returns the worker status
@@ -72,9 +111,49 @@ func (tw *TransferWorker) LastRun() time.Time {
 	return tw.worker.LastRun()
 }
 
+// Pause and Resume apply to both of TransferWorker's loops (transfer and
+// upload reconciliation), so an operator pausing "the transfer worker"
+// through the admin API stops both together.
+func (tw *TransferWorker) Pause() {
+	tw.worker.Pause()
+	tw.uploadReconciler.Pause()
+}
+
+func (tw *TransferWorker) Resume() {
+	tw.worker.Resume()
+	tw.uploadReconciler.Resume()
+}
+
+// IsPaused, Interval, BatchSize, and RetryCount report the transfer-queue
+// loop's state; the upload-reconciliation loop's own interval/batch size
+// are config-only and not exposed through the admin API.
+func (tw *TransferWorker) IsPaused() bool          { return tw.worker.IsPaused() }
+func (tw *TransferWorker) Interval() time.Duration { return tw.worker.Interval() }
+func (tw *TransferWorker) BatchSize() int          { return tw.worker.BatchSize() }
+func (tw *TransferWorker) RetryCount() int         { return tw.worker.RetryCount() }
+
+// Reconfigure applies new runtime tunables to the transfer-queue loop.
+// visibilityTimeout is ignored; TransferWorker has no visibility timeout
+// tunable of its own.
+func (tw *TransferWorker) Reconfigure(interval time.Duration, batchSize, retryCount int, visibilityTimeout time.Duration) {
+	tw.worker.Reconfigure(interval, batchSize, retryCount)
+}
+
+// processUploadReconciliation sweeps every configured destination for
+// multipart uploads left dangling by a crash or restart, aborting each
+// stale one per transfer.ReconcileStaleUploads and leaving the rest for the
+// next transfer attempt to resume. batchSize is
+// accepted to match the worker.ProcessFunc signature but unused: a sweep
+// always walks every blob rather than a bounded batch, since skipping a
+// stale upload today just means finding it again next interval.
+func (tw *TransferWorker) processUploadReconciliation(ctx context.Context, batchSize int) error {
+	return transfer.ReconcileStaleUploads(ctx, tw.blobClient, tw.volumes, tw.containerName, tw.uploadStaleTTL)
+}
+
 // processTransferQueue processes messages from the transfer queue
 func (tw *TransferWorker) processTransferQueue(ctx context.Context, batchSize int) error {
 	queueClient := tw.queueClient.NewQueueClient(tw.queueName)
+	observeQueueDepth(ctx, queueClient, tw.queueName, tw.metrics)
 
 	// Create options for dequeuing messages
 	options := &azqueue.DequeueMessagesOptions{
@@ -95,9 +174,13 @@ func (tw *TransferWorker) processTransferQueue(ctx context.Context, batchSize in
 	}
 
 	log.Printf("Received %d messages from transfer queue", len(resp.Messages))
+	tw.metrics.ObserveQueueDequeue(tw.queueName, tw.containerName, len(resp.Messages))
 
 	// Process each message
 	for _, msg := range resp.Messages {
+		if msg.InsertionTime != nil {
+			tw.metrics.ObserveQueueMessageAge(tw.queueName, tw.containerName, time.Since(*msg.InsertionTime))
+		}
 		// Extract the blob name from the message
 		// Need to check if MessageText is nil
 		if msg.MessageText == nil {
@@ -108,10 +191,24 @@ func (tw *TransferWorker) processTransferQueue(ctx context.Context, batchSize in
 		blobName := *msg.MessageText
 		log.Printf("Processing transfer message for blob: %s", blobName)
 
-		// Transfer the blob to S3
-		err := transfer.TransferValidatedBlob(ctx, tw.blobClient, tw.s3Client, tw.containerName, blobName)
+		// Transfer the blob to every configured destination
+		err := transfer.TransferValidatedBlob(ctx, tw.blobClient, tw.volumes, tw.containerName, blobName, tw.raceWindow)
 		if err != nil {
+			var raceErr *transfer.RaceWindowError
+			var precondErr *transfer.PreconditionFailedError
+			if errors.As(err, &raceErr) || errors.As(err, &precondErr) {
+				// Neither is a failure of the blob itself - just a sign that
+				// something else is touching it right now - so leave the
+				// message on the queue to become visible again rather than
+				// running it through dead-letter/backoff bookkeeping meant
+				// for genuine transfer failures.
+				log.Printf("Skipping blob %s for retry: %v", blobName, err)
+				continue
+			}
 			log.Printf("Failed to transfer blob %s: %v", blobName, err)
+			if dlqErr := handleDequeueFailure(ctx, queueClient, tw.queueName, msg, blobName, tw.blobMetadataSnapshot(ctx, blobName), err, tw.maxDeliveryCount, tw.baseBackoff, tw.maxBackoff, tw.failureTracker, tw.deadLetterSink, tw.metrics); dlqErr != nil {
+				log.Printf("Failed to handle transfer failure for blob %s: %v", blobName, dlqErr)
+			}
 			continue
 		}
 
@@ -128,12 +225,17 @@ func (tw *TransferWorker) processTransferQueue(ctx context.Context, batchSize in
 			continue
 		}
 
+		// The message succeeded, possibly after earlier transient failures -
+		// drop its tracked attempts so messageFailureTracker doesn't hold an
+		// entry for it forever.
+		tw.failureTracker.forget(*msg.MessageID)
+
 		// Log the transfer for audit purposes
 		if err := tw.logTransfer(ctx, blobName); err != nil {
 			log.Printf("Failed to log transfer for blob %s: %v", blobName, err)
 		}
 
-		log.Printf("Blob %s transferred successfully to S3", blobName)
+		log.Printf("Blob %s transferred successfully", blobName)
 
 		// Queue cleanup task if needed
 		cleanupQueueName := "cleanup-queue"
@@ -158,6 +260,26 @@ func (tw *TransferWorker) logTransfer(ctx context.Context, blobName string) erro
 	return nil
 }
 
+// blobMetadataSnapshot best-effort fetches a blob's current metadata for
+// inclusion in a DeadLetterRecord. Failures are logged and swallowed since
+// this is diagnostic context, not required for correctness.
+func (tw *TransferWorker) blobMetadataSnapshot(ctx context.Context, blobName string) map[string]string {
+	blobClient := tw.blobClient.ServiceClient().NewContainerClient(tw.containerName).NewBlobClient(blobName)
+	props, err := blobClient.GetProperties(ctx, nil)
+	if err != nil {
+		log.Printf("Failed to snapshot metadata for blob %s: %v", blobName, err)
+		return nil
+	}
+
+	metadata := make(map[string]string, len(props.Metadata))
+	for k, v := range props.Metadata {
+		if v != nil {
+			metadata[k] = *v
+		}
+	}
+	return metadata
+}
+
 // QueueTransferTaskInternal adds a blob to the transfer queue - for internal use
 func QueueTransferTaskInternal(ctx context.Context, queueClient *azqueue.ServiceClient, queueName, blobName string) error {
 	client := queueClient.NewQueueClient(queueName)
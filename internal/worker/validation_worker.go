@@ -5,34 +5,63 @@ import (
 	"fmt"
 	"log"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/CAST-Intelligence/elysium-usv/internal/config"
+	"github.com/CAST-Intelligence/elysium-usv/internal/metrics"
+	"github.com/CAST-Intelligence/elysium-usv/internal/storage"
 	"github.com/CAST-Intelligence/elysium-usv/internal/validation"
-	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob"
 	"github.com/Azure/azure-sdk-for-go/sdk/storage/azqueue"
 )
 
 // ValidationWorker processes blobs for validation
 type ValidationWorker struct {
-	worker       *Worker
-	blobClient   *azblob.Client
-	queueClient  *azqueue.ServiceClient
-	containerName string
-	queueName     string
+	worker           *Worker
+	objectStore      storage.ObjectStore
+	queueClient      *azqueue.ServiceClient
+	containerName    string
+	queueName        string
+	deadLetterSink   DeadLetterSink
+	maxDeliveryCount int32
+	baseBackoff      time.Duration
+	maxBackoff       time.Duration
+	failureTracker   *messageFailureTracker
+	metrics          *metrics.Registry
+
+	// tunablesMu guards visibilityTimeout, the one tunable the admin reload
+	// endpoint can change at runtime; visibilityRefreshInterval is set once
+	// at construction and never changes.
+	tunablesMu                sync.RWMutex
+	visibilityTimeout         time.Duration
+	visibilityRefreshInterval time.Duration
 }
 
-// NewValidationWorker creates a new worker for blob validation
+// NewValidationWorker creates a new worker for blob validation. objectStore
+// is the storage.ObjectStore selected by cfg.StorageDriver (typically built
+// with storage.NewObjectStore), so this worker can run against Azure Blob
+// Storage or a local directory without depending on the Azure SDK directly.
+// reg may be nil.
 func NewValidationWorker(
 	cfg *config.Config,
-	blobClient *azblob.Client,
+	objectStore storage.ObjectStore,
 	queueClient *azqueue.ServiceClient,
+	reg *metrics.Registry,
 ) *ValidationWorker {
 	vw := &ValidationWorker{
-		blobClient:    blobClient,
-		queueClient:   queueClient,
-		containerName: cfg.BlobContainerName,
-		queueName:     cfg.ValidationQueueName,
+		objectStore:      objectStore,
+		queueClient:      queueClient,
+		containerName:    cfg.BlobContainerName,
+		queueName:        cfg.ValidationQueueName,
+		deadLetterSink:   NewAzureQueueDeadLetterSink(queueClient, cfg.DeadLetterQueueName),
+		maxDeliveryCount: cfg.MaxDeliveryCount,
+		baseBackoff:      cfg.DeadLetterBaseBackoff,
+		maxBackoff:       cfg.DeadLetterMaxBackoff,
+		failureTracker:   newMessageFailureTracker(),
+		metrics:          reg,
+
+		visibilityTimeout:         cfg.VisibilityTimeout,
+		visibilityRefreshInterval: cfg.VisibilityRefreshInterval,
 	}
 
 	// Create the underlying worker
@@ -42,6 +71,7 @@ func NewValidationWorker(
 		30*time.Second, // Poll every 30 seconds
 		cfg.ProcessingBatchSize,
 		cfg.OperationRetryCount,
+		reg,
 	)
 
 	vw.worker = worker
@@ -68,14 +98,56 @@ func (vw *ValidationWorker) LastRun() time.Time {
 	return vw.worker.LastRun()
 }
 
+// DeadLetterSink returns the sink this worker moves poison messages to,
+// for callers (e.g. the server package's /api/v1/poison endpoint) that
+// need to list dead-lettered messages. All three workers share the same
+// underlying queue (cfg.DeadLetterQueueName), so any one of them can back
+// that listing.
+func (vw *ValidationWorker) DeadLetterSink() DeadLetterSink {
+	return vw.deadLetterSink
+}
+
+// Pause, Resume, IsPaused, Interval, BatchSize, and RetryCount forward to
+// the underlying Worker, so ValidationWorker satisfies server.ManagedWorker.
+func (vw *ValidationWorker) Pause()                { vw.worker.Pause() }
+func (vw *ValidationWorker) Resume()                { vw.worker.Resume() }
+func (vw *ValidationWorker) IsPaused() bool         { return vw.worker.IsPaused() }
+func (vw *ValidationWorker) Interval() time.Duration { return vw.worker.Interval() }
+func (vw *ValidationWorker) BatchSize() int         { return vw.worker.BatchSize() }
+func (vw *ValidationWorker) RetryCount() int        { return vw.worker.RetryCount() }
+
+// VisibilityTimeout reports the timeout currently applied to dequeued
+// validation messages. It implements server.VisibilityTimeoutReporter.
+func (vw *ValidationWorker) VisibilityTimeout() time.Duration {
+	vw.tunablesMu.RLock()
+	defer vw.tunablesMu.RUnlock()
+	return vw.visibilityTimeout
+}
+
+// Reconfigure applies new runtime tunables, picked up on the next dequeue
+// loop iteration without a restart. A zero value leaves the corresponding
+// field unchanged.
+func (vw *ValidationWorker) Reconfigure(interval time.Duration, batchSize, retryCount int, visibilityTimeout time.Duration) {
+	vw.worker.Reconfigure(interval, batchSize, retryCount)
+	if visibilityTimeout > 0 {
+		vw.tunablesMu.Lock()
+		vw.visibilityTimeout = visibilityTimeout
+		vw.tunablesMu.Unlock()
+	}
+}
+
 // processValidationQueue processes messages from the validation queue
 func (vw *ValidationWorker) processValidationQueue(ctx context.Context, batchSize int) error {
 	queueClient := vw.queueClient.NewQueueClient(vw.queueName)
+	observeQueueDepth(ctx, queueClient, vw.queueName, vw.metrics)
+
+	visibilityTimeout := vw.VisibilityTimeout()
 
 	// Create options for dequeuing messages
+	visibilitySeconds := int32(visibilityTimeout.Seconds())
 	options := &azqueue.DequeueMessagesOptions{
-		NumberOfMessages: &[]int32{int32(batchSize)}[0], // Convert batch size to int32 pointer
-		VisibilityTimeout: &[]int32{30}[0], // 30 seconds visibility timeout
+		NumberOfMessages:  &[]int32{int32(batchSize)}[0], // Convert batch size to int32 pointer
+		VisibilityTimeout: &visibilitySeconds,
 	}
 	
 	// Dequeue messages from the queue
@@ -91,6 +163,7 @@ func (vw *ValidationWorker) processValidationQueue(ctx context.Context, batchSiz
 	}
 	
 	log.Printf("Received %d messages from validation queue", len(resp.Messages))
+	vw.metrics.ObserveQueueDequeue(vw.queueName, vw.containerName, len(resp.Messages))
 
 	// Process each message
 	for _, msg := range resp.Messages {
@@ -100,30 +173,63 @@ func (vw *ValidationWorker) processValidationQueue(ctx context.Context, batchSiz
 			log.Printf("Received message with nil MessageText, skipping")
 			continue
 		}
-		
+		if msg.InsertionTime != nil {
+			vw.metrics.ObserveQueueMessageAge(vw.queueName, vw.containerName, time.Since(*msg.InsertionTime))
+		}
+
 		blobName := *msg.MessageText
 		log.Printf("Processing validation message for blob: %s", blobName)
-		
-		// Validate the blob
-		isValid, err := validation.ValidateBlob(ctx, vw.blobClient, vw.containerName, blobName)
+
+		// Need to check if MessageID and PopReceipt are nil before starting
+		// a lease refresher, since both are required to renew or complete
+		// the message.
+		if msg.MessageID == nil || msg.PopReceipt == nil {
+			log.Printf("Received message with nil MessageID or PopReceipt, skipping")
+			continue
+		}
+
+		// Validation of large blobs can easily exceed a single visibility
+		// timeout, so keep the message leased with a background refresher
+		// for as long as validation is running. If a renewal fails, cancel
+		// messageCtx so ValidateBlob aborts rather than keep working on a
+		// message another worker may already be reprocessing.
+		messageCtx, cancelMessage := context.WithCancel(ctx)
+		lease := newPopReceiptLease(*msg.PopReceipt)
+		stopRefresher := startVisibilityRefresher(
+			ctx, queueClient, *msg.MessageID, blobName, lease,
+			visibilityTimeout, vw.visibilityRefreshInterval, cancelMessage,
+		)
+
+		validateStart := time.Now()
+		isValid, err := validation.ValidateBlob(messageCtx, vw.objectStore, blobName)
+		vw.metrics.ObserveBlobValidate(vw.containerName, time.Since(validateStart))
+		stopRefresher()
+		cancelMessage()
+
+		currentPopReceipt := lease.get()
+		msg.PopReceipt = &currentPopReceipt
+
 		if err != nil {
 			log.Printf("Failed to validate blob %s: %v", blobName, err)
+			if dlqErr := handleDequeueFailure(ctx, queueClient, vw.queueName, msg, blobName, nil, err, vw.maxDeliveryCount, vw.baseBackoff, vw.maxBackoff, vw.failureTracker, vw.deadLetterSink, vw.metrics); dlqErr != nil {
+				log.Printf("Failed to handle validation failure for blob %s: %v", blobName, dlqErr)
+			}
 			continue
 		}
 
 		// If validation done (success or failure), delete the message from the queue
-		// Need to check if MessageID and PopReceipt are nil
-		if msg.MessageID == nil || msg.PopReceipt == nil {
-			log.Printf("Received message with nil MessageID or PopReceipt, skipping")
-			continue
-		}
-		
-		_, err = queueClient.DeleteMessage(ctx, *msg.MessageID, *msg.PopReceipt, nil)
+		_, err = queueClient.DeleteMessage(ctx, *msg.MessageID, currentPopReceipt, nil)
 		if err != nil {
 			log.Printf("Failed to delete message for blob %s: %v", blobName, err)
 			continue
 		}
 
+		// The message was processed (validated or rejected) without error,
+		// possibly after earlier transient failures - drop its tracked
+		// attempts so messageFailureTracker doesn't hold an entry for it
+		// forever.
+		vw.failureTracker.forget(*msg.MessageID)
+
 		// If valid, add to the transfer queue
 		if isValid {
 			log.Printf("Blob %s validated successfully, adding to transfer queue", blobName)
@@ -0,0 +1,92 @@
+package worker
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azqueue"
+)
+
+// popReceiptLease tracks the pop receipt for a single in-flight dequeued
+// message as a background refresher keeps renewing it, so callers that
+// later need the pop receipt to delete or dead-letter the message always
+// see the most recently renewed one rather than the one handed out at
+// dequeue time, which Azure invalidates on every UpdateMessage call.
+type popReceiptLease struct {
+	mu         sync.Mutex
+	popReceipt string
+}
+
+func newPopReceiptLease(initial string) *popReceiptLease {
+	return &popReceiptLease{popReceipt: initial}
+}
+
+func (l *popReceiptLease) get() string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.popReceipt
+}
+
+func (l *popReceiptLease) set(popReceipt string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.popReceipt = popReceipt
+}
+
+// startVisibilityRefresher spawns a goroutine that periodically extends a
+// dequeued message's visibility timeout at refreshInterval (normally
+// ~50% of visibilityTimeout), analogous to a MinIO-style lock refresh: it
+// keeps the message invisible to other workers for as long as this one is
+// still processing it, renewing lease with the pop receipt each
+// UpdateMessage call returns. If a renewal fails (e.g. the pop receipt is
+// no longer valid because the message was already completed or
+// redelivered elsewhere), it calls onRenewFailure so the caller can
+// abandon the in-flight work, then stops.
+//
+// The caller must invoke the returned stop function once processing
+// finishes, successfully or not, before it tries to delete or dead-letter
+// the message.
+func startVisibilityRefresher(
+	ctx context.Context,
+	queueClient *azqueue.QueueClient,
+	messageID, messageText string,
+	lease *popReceiptLease,
+	visibilityTimeout, refreshInterval time.Duration,
+	onRenewFailure func(),
+) (stop func()) {
+	stopCh := make(chan struct{})
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(refreshInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				visibilitySeconds := int32(visibilityTimeout.Seconds())
+				resp, err := queueClient.UpdateMessage(ctx, messageID, lease.get(), visibilitySeconds, messageText, nil)
+				if err != nil {
+					log.Printf("Failed to renew visibility timeout for message %s, abandoning in-flight processing: %v", messageID, err)
+					onRenewFailure()
+					return
+				}
+				if resp.PopReceipt != nil {
+					lease.set(*resp.PopReceipt)
+				}
+			case <-stopCh:
+				return
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return func() {
+		close(stopCh)
+		<-done
+	}
+}
@@ -6,6 +6,8 @@ import (
 	"log"
 	"sync"
 	"time"
+
+	"github.com/CAST-Intelligence/elysium-usv/internal/metrics"
 )
 
 // Worker represents a background worker that processes tasks
@@ -15,19 +17,24 @@ type Worker struct {
 	interval   time.Duration
 	batchSize  int
 	retryCount int
+	paused     bool
+	metrics    *metrics.Registry
 	ctx        context.Context
 	cancel     context.CancelFunc
 	wg         sync.WaitGroup
 	status     string
 	lastRun    time.Time
 	mu         sync.RWMutex
+	wake       chan struct{}
 }
 
 // ProcessFunc is a function that processes a batch of work
 type ProcessFunc func(ctx context.Context, batchSize int) error
 
-// NewWorker creates a new worker with the given name and processing function
-func NewWorker(name string, processFn ProcessFunc, interval time.Duration, batchSize, retryCount int) *Worker {
+// NewWorker creates a new worker with the given name and processing
+// function. reg may be nil, in which case the worker simply isn't
+// instrumented.
+func NewWorker(name string, processFn ProcessFunc, interval time.Duration, batchSize, retryCount int, reg *metrics.Registry) *Worker {
 	ctx, cancel := context.WithCancel(context.Background())
 	return &Worker{
 		name:       name,
@@ -35,10 +42,12 @@ func NewWorker(name string, processFn ProcessFunc, interval time.Duration, batch
 		interval:   interval,
 		batchSize:  batchSize,
 		retryCount: retryCount,
+		metrics:    reg,
 		ctx:        ctx,
 		cancel:     cancel,
 		status:     "stopped",
 		lastRun:    time.Time{},
+		wake:       make(chan struct{}, 1),
 	}
 }
 
@@ -47,6 +56,7 @@ func (w *Worker) Start() {
 	w.mu.Lock()
 	w.status = "running"
 	w.mu.Unlock()
+	w.metrics.SetWorkerUp(w.name, true)
 
 	w.wg.Add(1)
 	go func() {
@@ -63,6 +73,7 @@ func (w *Worker) Stop() {
 	w.mu.Lock()
 	w.status = "stopped"
 	w.mu.Unlock()
+	w.metrics.SetWorkerUp(w.name, false)
 	log.Printf("Worker %s stopped", w.name)
 }
 
@@ -80,17 +91,93 @@ func (w *Worker) LastRun() time.Time {
 	return w.lastRun
 }
 
-// run is the main worker loop
-func (w *Worker) run() {
-	ticker := time.NewTicker(w.interval)
-	defer ticker.Stop()
+// Pause stops the worker from processing on its next tick, without
+// canceling its context or stopping its goroutine, so Resume can pick back
+// up without losing in-flight state. run's loop keeps ticking underneath;
+// process() just returns immediately while paused.
+func (w *Worker) Pause() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.paused = true
+	w.status = "paused"
+}
+
+// Resume un-pauses a paused worker.
+func (w *Worker) Resume() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.paused = false
+	w.status = "running"
+}
+
+// IsPaused reports whether the worker is currently paused.
+func (w *Worker) IsPaused() bool {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	return w.paused
+}
+
+// Interval, BatchSize, and RetryCount report the worker's current tunables,
+// for admin endpoints that display effective config.
+func (w *Worker) Interval() time.Duration {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	return w.interval
+}
 
+func (w *Worker) BatchSize() int {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	return w.batchSize
+}
+
+func (w *Worker) RetryCount() int {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	return w.retryCount
+}
+
+// Reconfigure updates the worker's tunables in place, picked up on the next
+// tick without a process restart. A zero value leaves the corresponding
+// field unchanged, so a caller that only wants to change batch size can
+// pass a zero interval and retryCount.
+func (w *Worker) Reconfigure(interval time.Duration, batchSize, retryCount int) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if interval > 0 {
+		w.interval = interval
+	}
+	if batchSize > 0 {
+		w.batchSize = batchSize
+	}
+	if retryCount > 0 {
+		w.retryCount = retryCount
+	}
+}
+
+// Wake schedules an extra process() run as soon as possible, without
+// waiting for the current poll interval to elapse. Safe to call
+// concurrently, including before Start(); extra wakes while one is already
+// pending are coalesced into a single run.
+func (w *Worker) Wake() {
+	select {
+	case w.wake <- struct{}{}:
+	default:
+	}
+}
+
+// run is the main worker loop. It re-reads the interval before every sleep
+// (rather than building a single ticker up front) so a Reconfigure call
+// takes effect starting with the next tick instead of requiring a restart.
+func (w *Worker) run() {
 	// Process immediately on start
 	w.process()
 
 	for {
 		select {
-		case <-ticker.C:
+		case <-time.After(w.Interval()):
+			w.process()
+		case <-w.wake:
 			w.process()
 		case <-w.ctx.Done():
 			return
@@ -100,31 +187,45 @@ func (w *Worker) run() {
 
 // process executes the processing function with retry logic
 func (w *Worker) process() {
-	for attempt := 0; attempt < w.retryCount; attempt++ {
-		err := w.processFn(w.ctx, w.batchSize)
-		
+	if w.IsPaused() {
+		return
+	}
+
+	retryCount := w.RetryCount()
+	batchSize := w.BatchSize()
+	for attempt := 0; attempt < retryCount; attempt++ {
+		start := time.Now()
+		err := w.processFn(w.ctx, batchSize)
+		duration := time.Since(start)
+
 		// Update the last run time
+		now := time.Now()
 		w.mu.Lock()
-		w.lastRun = time.Now()
+		w.lastRun = now
 		w.mu.Unlock()
-		
+		w.metrics.SetWorkerLastRun(w.name, now)
+
+		final := err == nil || attempt == retryCount-1
+		w.metrics.ObserveProcess(w.name, duration, final, err)
+
 		if err == nil {
 			// Success, break the retry loop
 			return
 		}
-		
+
 		// Log the error and retry
-		log.Printf("Worker %s error (attempt %d/%d): %v", w.name, attempt+1, w.retryCount, err)
-		
+		log.Printf("Worker %s error (attempt %d/%d): %v", w.name, attempt+1, retryCount, err)
+
 		// If this was the last attempt, update status to error
-		if attempt == w.retryCount-1 {
+		if attempt == retryCount-1 {
 			w.mu.Lock()
 			w.status = fmt.Sprintf("error: %v", err)
 			w.mu.Unlock()
 			return
 		}
-		
+
 		// Wait before retrying, with exponential backoff
+		w.metrics.ObserveRetry(w.name)
 		backoff := time.Duration(attempt+1) * time.Second
 		time.Sleep(backoff)
 	}